@@ -0,0 +1,500 @@
+package graphml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// NormalizeWeights linearly rescales the values of the edges weight attribute with given name into the [min, max]
+// range based on the minimal and maximal values observed among the edges of this graph. The edges without weight
+// attribute value use the default value of the key if present. Otherwise, such edges are either skipped if skipMissing
+// is set, or error returned. The weight key must be of float or double type to hold the normalized values.
+func (gr *Graph) NormalizeWeights(weightKey string, min, max float64, skipMissing bool) error {
+	if min > max {
+		return errors.New(fmt.Sprintf("wrong normalization range: [%v, %v]", min, max))
+	}
+	key := gr.parent.GetKey(weightKey, KeyForEdge)
+	if key == nil {
+		return errors.New(fmt.Sprintf("weight key not found: %s", weightKey))
+	}
+	if key.KeyType != FloatType && key.KeyType != DoubleType {
+		return errors.New(fmt.Sprintf("weight key must be of float or double type, found: %s", key.KeyType))
+	}
+
+	// collect weights
+	edges := make([]*Edge, 0, len(gr.Edges))
+	weights := make([]float64, 0, len(gr.Edges))
+	for _, e := range gr.Edges {
+		attrs, err := e.GetAttributes()
+		if err != nil {
+			return err
+		}
+		value, ok := attrs[weightKey]
+		if !ok {
+			if skipMissing {
+				continue
+			}
+			return errors.New(fmt.Sprintf("edge: %s has no weight attribute: %s", e.ID, weightKey))
+		}
+		w, err := floatValue(value)
+		if err != nil {
+			return err
+		}
+		edges = append(edges, e)
+		weights = append(weights, w)
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+
+	// find observed range
+	observedMin, observedMax := weights[0], weights[0]
+	for _, w := range weights[1:] {
+		if w < observedMin {
+			observedMin = w
+		}
+		if w > observedMax {
+			observedMax = w
+		}
+	}
+
+	// rescale and store
+	for i, e := range edges {
+		normalized := min
+		if observedMax > observedMin {
+			normalized = min + (weights[i]-observedMin)*(max-min)/(observedMax-observedMin)
+		}
+		if err := e.SetAttribute(weightKey, normalized); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Weight returns the value of the numeric attribute with given name of this edge converted to float64 regardless of
+// the type of its key. The default value of the key is used if edge has no data for the attribute. Returns error if
+// attribute not found or its value is not numeric.
+func (e *Edge) Weight(keyName string) (float64, error) {
+	value, found, err := e.GetAttribute(keyName)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, errors.New(fmt.Sprintf("edge: %s has no weight attribute: %s", e.ID, keyName))
+	}
+	return floatValue(value)
+}
+
+// SetWeight sets the value of the numeric attribute with given name of this edge. The value is converted to the type
+// of the key if it is already registered, e.g., whole numbers are stored by int/long keys. Otherwise, new key of
+// double type is registered.
+func (e *Edge) SetWeight(keyName string, w float64) error {
+	return e.SetAttribute(keyName, w)
+}
+
+// floatValue converts provided numeric value to float64
+func floatValue(value interface{}) (float64, error) {
+	if value == nil {
+		return 0, errors.New("numeric value expected, found: nil")
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return 0, errors.New(fmt.Sprintf("numeric value expected, found: %T", value))
+	}
+}
+
+// DegreeAssortativity computes the degree assortativity coefficient of this graph, i.e., the Pearson correlation
+// coefficient of degrees of nodes at either end of edges. The graph is treated as undirected. Returns error
+// if graph has less than two edges or coefficient is undefined, e.g., when all nodes have the same degree.
+func (gr *Graph) DegreeAssortativity() (float64, error) {
+	if len(gr.Edges) < 2 {
+		return 0, errors.New("at least two edges required to compute degree assortativity")
+	}
+	degrees := gr.degrees()
+	xs := make([]float64, 0, len(gr.Edges)*2)
+	ys := make([]float64, 0, len(gr.Edges)*2)
+	for _, e := range gr.Edges {
+		// every undirected edge contributes its degrees pair in both orders
+		xs = append(xs, float64(degrees[e.Source]), float64(degrees[e.Target]))
+		ys = append(ys, float64(degrees[e.Target]), float64(degrees[e.Source]))
+	}
+	return pearsonCorrelation(xs, ys)
+}
+
+// DirectedDegreeAssortativity computes the directed degree assortativity coefficient of this graph, i.e., the Pearson
+// correlation coefficient of the out-degree of the source node and the in-degree of the target node over all edges.
+// Every edge is considered as directed from its source to its target. Returns error if graph has less than two edges
+// or coefficient is undefined.
+func (gr *Graph) DirectedDegreeAssortativity() (float64, error) {
+	if len(gr.Edges) < 2 {
+		return 0, errors.New("at least two edges required to compute degree assortativity")
+	}
+	outDegrees := make(map[string]int)
+	inDegrees := make(map[string]int)
+	for _, e := range gr.Edges {
+		outDegrees[e.Source]++
+		inDegrees[e.Target]++
+	}
+	xs := make([]float64, len(gr.Edges))
+	ys := make([]float64, len(gr.Edges))
+	for i, e := range gr.Edges {
+		xs[i] = float64(outDegrees[e.Source])
+		ys[i] = float64(inDegrees[e.Target])
+	}
+	return pearsonCorrelation(xs, ys)
+}
+
+// pearsonCorrelation computes the Pearson correlation coefficient between provided samples of equal length
+func pearsonCorrelation(xs, ys []float64) (float64, error) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, errors.New("correlation is undefined for samples with zero variance")
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
+
+// HasCycle checks if this graph has a cycle. The undirected edges can be traversed in both directions, but the same
+// edge can not be traversed twice. Returns error if any edge refers to the unknown node.
+func (gr *Graph) HasCycle() (bool, error) {
+	cycle, err := gr.FindCycleEdges()
+	return cycle != nil, err
+}
+
+// FindCycleEdges looks for a cycle in this graph using the depth-first search. The nodes are visited in order of their
+// appearance in the graph, and the edges of each node in order of their appearance, thus the same cycle is reported for
+// the same graph. The undirected edges can be traversed in both directions, but the same edge can not be traversed
+// twice. Returns the edges of the first detected cycle in order of traversal, or nil if graph is acyclic. Returns error
+// if any edge refers to the unknown node.
+func (gr *Graph) FindCycleEdges() ([]*Edge, error) {
+	type arc struct {
+		edge *Edge
+		to   string
+	}
+	arcs := make(map[string][]arc)
+	for _, e := range gr.Edges {
+		if gr.GetNode(e.Source) == nil {
+			return nil, errors.New(fmt.Sprintf("source node: %s of edge: %s not found", e.Source, e.ID))
+		}
+		if gr.GetNode(e.Target) == nil {
+			return nil, errors.New(fmt.Sprintf("target node: %s of edge: %s not found", e.Target, e.ID))
+		}
+		arcs[e.Source] = append(arcs[e.Source], arc{edge: e, to: e.Target})
+		if !e.directed() && e.Source != e.Target {
+			arcs[e.Target] = append(arcs[e.Target], arc{edge: e, to: e.Source})
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	// the position of the node on the current path, i.e., the index of the edge leaving it in the path
+	position := make(map[string]int)
+	path := make([]*Edge, 0)
+
+	var visit func(id string, via *Edge) []*Edge
+	visit = func(id string, via *Edge) []*Edge {
+		state[id] = visiting
+		position[id] = len(path)
+		for _, a := range arcs[id] {
+			if a.edge == via {
+				// do not traverse the same edge back
+				continue
+			}
+			switch state[a.to] {
+			case visiting:
+				cycle := make([]*Edge, 0, len(path)-position[a.to]+1)
+				cycle = append(cycle, path[position[a.to]:]...)
+				return append(cycle, a.edge)
+			case 0:
+				path = append(path, a.edge)
+				if cycle := visit(a.to, a.edge); cycle != nil {
+					return cycle
+				}
+				path = path[:len(path)-1]
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, n := range gr.Nodes {
+		if state[n.ID] == 0 {
+			if cycle := visit(n.ID, nil); cycle != nil {
+				return cycle, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ConnectedComponents finds the connected components of this graph ignoring direction of edges. The components are
+// ordered by appearance of their first node in the graph, and the nodes of each component are in order of their
+// appearance in the graph. The edges referring unknown nodes are ignored.
+func (gr *Graph) ConnectedComponents() [][]*Node {
+	neighbors := make(map[string][]string)
+	for _, e := range gr.Edges {
+		neighbors[e.Source] = append(neighbors[e.Source], e.Target)
+		neighbors[e.Target] = append(neighbors[e.Target], e.Source)
+	}
+	component := make(map[string]int)
+	count := 0
+	for _, n := range gr.Nodes {
+		if _, ok := component[n.ID]; ok {
+			continue
+		}
+		component[n.ID] = count
+		queue := []string{n.ID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, next := range neighbors[id] {
+				if _, ok := component[next]; !ok && gr.GetNode(next) != nil {
+					component[next] = count
+					queue = append(queue, next)
+				}
+			}
+		}
+		count++
+	}
+	res := make([][]*Node, count)
+	for _, n := range gr.Nodes {
+		res[component[n.ID]] = append(res[component[n.ID]], n)
+	}
+	return res
+}
+
+// CanReach checks if the node with targetID is reachable from the node with sourceID following directions of edges.
+// The undirected edges can be traversed in both directions. Every node is reachable from itself. Returns error if any
+// of the nodes not found.
+func (gr *Graph) CanReach(sourceID, targetID string) (bool, error) {
+	if err := gr.checkNodesExist(sourceID, targetID); err != nil {
+		return false, err
+	}
+	reached := reachableNodes(gr.successors(), sourceID, targetID)
+	return reached[targetID], nil
+}
+
+// ReachabilityIndex The precomputed reachability between all nodes of the graph, i.e., its transitive closure.
+// The index reflects the state of the graph at the time of creation and is not updated with graph changes.
+type ReachabilityIndex struct {
+	graph     *Graph
+	reachable map[string]map[string]bool
+}
+
+// NewReachabilityIndex creates reachability index for this graph to answer repeated reachability queries in
+// constant time.
+func (gr *Graph) NewReachabilityIndex() *ReachabilityIndex {
+	successors := gr.successors()
+	index := &ReachabilityIndex{
+		graph:     gr,
+		reachable: make(map[string]map[string]bool, len(gr.Nodes)),
+	}
+	for _, n := range gr.Nodes {
+		index.reachable[n.ID] = reachableNodes(successors, n.ID, "")
+	}
+	return index
+}
+
+// CanReach checks if the node with targetID is reachable from the node with sourceID (see Graph.CanReach).
+// Returns error if any of the nodes not found.
+func (ri *ReachabilityIndex) CanReach(sourceID, targetID string) (bool, error) {
+	if _, ok := ri.reachable[sourceID]; !ok {
+		return false, fmt.Errorf("%w: %s", ErrNodeNotFound, sourceID)
+	}
+	if _, ok := ri.reachable[targetID]; !ok {
+		return false, fmt.Errorf("%w: %s", ErrNodeNotFound, targetID)
+	}
+	return ri.reachable[sourceID][targetID], nil
+}
+
+// checkNodesExist returns error if any of the nodes with given IDs not found in this graph
+func (gr *Graph) checkNodesExist(ids ...string) error {
+	for _, id := range ids {
+		if gr.GetNode(id) == nil {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+		}
+	}
+	return nil
+}
+
+// successors builds the map of successors of each node following directions of edges. The undirected edges are
+// traversed in both directions.
+func (gr *Graph) successors() map[string][]string {
+	res := make(map[string][]string)
+	for _, e := range gr.Edges {
+		res[e.Source] = append(res[e.Source], e.Target)
+		if !e.directed() {
+			res[e.Target] = append(res[e.Target], e.Source)
+		}
+	}
+	return res
+}
+
+// reachableNodes finds the nodes reachable from the node with given ID using breadth-first search. The search stops
+// as soon as the node with stopID is reached.
+func reachableNodes(successors map[string][]string, id, stopID string) map[string]bool {
+	reached := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 && !reached[stopID] {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[current] {
+			if !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reached
+}
+
+// IsolatedNodes returns the nodes of this graph having no incident edges, sorted by their IDs. The node with
+// self-loop is not isolated.
+func (gr *Graph) IsolatedNodes() []*Node {
+	connected := make(map[string]bool)
+	for _, e := range gr.Edges {
+		connected[e.Source] = true
+		connected[e.Target] = true
+	}
+	res := make([]*Node, 0)
+	for _, n := range gr.Nodes {
+		if !connected[n.ID] {
+			res = append(res, n)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// DegreeCount The number of nodes having particular degree
+type DegreeCount struct {
+	// The degree of nodes
+	Degree int
+	// The number of nodes with this degree
+	Count int
+}
+
+// DegreeDistribution computes the degree distribution of this graph treated as undirected, i.e., the number of nodes
+// for each degree value. The self-loop adds two to the degree of its node.
+func (gr *Graph) DegreeDistribution() map[int]int {
+	return gr.distribution(gr.degrees())
+}
+
+// degrees computes the degrees of nodes of this graph treated as undirected indexed by node IDs. The self-loop adds
+// two to the degree of its node.
+func (gr *Graph) degrees() map[string]int {
+	degrees := make(map[string]int)
+	for _, e := range gr.Edges {
+		degrees[e.Source]++
+		degrees[e.Target]++
+	}
+	return degrees
+}
+
+// InDegreeDistribution computes the distribution of in-degrees of nodes of this graph, i.e., the number of nodes
+// for each in-degree value. Every edge is considered as directed from its source to its target.
+func (gr *Graph) InDegreeDistribution() map[int]int {
+	degrees := make(map[string]int)
+	for _, e := range gr.Edges {
+		degrees[e.Target]++
+	}
+	return gr.distribution(degrees)
+}
+
+// OutDegreeDistribution computes the distribution of out-degrees of nodes of this graph, i.e., the number of nodes
+// for each out-degree value. Every edge is considered as directed from its source to its target.
+func (gr *Graph) OutDegreeDistribution() map[int]int {
+	degrees := make(map[string]int)
+	for _, e := range gr.Edges {
+		degrees[e.Source]++
+	}
+	return gr.distribution(degrees)
+}
+
+// distribution counts the nodes of this graph for each degree value using provided degrees of nodes
+func (gr *Graph) distribution(degrees map[string]int) map[int]int {
+	res := make(map[int]int)
+	for _, n := range gr.Nodes {
+		res[degrees[n.ID]]++
+	}
+	return res
+}
+
+// SortedDegreeDistribution converts provided degree distribution into the list of degree counts sorted by degree
+func SortedDegreeDistribution(distribution map[int]int) []DegreeCount {
+	res := make([]DegreeCount, 0, len(distribution))
+	for degree, count := range distribution {
+		res = append(res, DegreeCount{Degree: degree, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Degree < res[j].Degree
+	})
+	return res
+}
+
+// GraphStats The summary statistics of the graph
+type GraphStats struct {
+	// The number of nodes
+	Nodes int
+	// The number of edges
+	Edges int
+	// The number of edges connecting the node with itself
+	SelfLoops int
+	// The maximal degree among nodes, where the self-loop adds two to the degree of its node
+	MaxDegree int
+	// The flag to indicate whether edges of the graph are directed by default
+	Directed bool
+}
+
+// String returns the text representation of statistics suitable for logging
+func (gs GraphStats) String() string {
+	return fmt.Sprintf("nodes: %d, edges: %d, self-loops: %d, max degree: %d, directed: %t",
+		gs.Nodes, gs.Edges, gs.SelfLoops, gs.MaxDegree, gs.Directed)
+}
+
+// Stats computes the summary statistics of this graph. The graph without default edge direction is directed.
+func (gr *Graph) Stats() GraphStats {
+	res := GraphStats{
+		Nodes:    len(gr.Nodes),
+		Edges:    len(gr.Edges),
+		Directed: gr.edgesDirection != EdgeDirectionUndirected,
+	}
+	for _, e := range gr.Edges {
+		if e.Source == e.Target {
+			res.SelfLoops++
+		}
+	}
+	degrees := gr.degrees()
+	for _, n := range gr.Nodes {
+		if degrees[n.ID] > res.MaxDegree {
+			res.MaxDegree = degrees[n.ID]
+		}
+	}
+	return res
+}
@@ -0,0 +1,378 @@
+package graphml
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEdge_Weight(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForEdge, "cost", "", reflect.Int, 3)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+	e, err := gr.AddEdge(n1, n2, map[string]interface{}{"weight": float32(0.5), "count": int64(7), "name": "e"}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	for name, expected := range map[string]float64{"weight": 0.5, "count": 7, "cost": 3} {
+		w, err := e.Weight(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, w, name)
+	}
+	_, err = e.Weight("name")
+	assert.EqualError(t, err, "numeric value expected, found: string")
+	_, err = e.Weight("unknown")
+	assert.EqualError(t, err, "edge: e0 has no weight attribute: unknown")
+
+	// set
+	err = e.SetWeight("count", 12)
+	require.NoError(t, err)
+	err = e.SetWeight("cost", 2.5)
+	assert.Error(t, err, "fractional value can not be stored by int key")
+	err = e.SetWeight("distance", 2.5)
+	require.NoError(t, err)
+	attrs, err := e.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), attrs["count"])
+	assert.Equal(t, 3, attrs["cost"])
+	assert.Equal(t, 2.5, attrs["distance"])
+	assert.Equal(t, DoubleType, gml.GetKey("distance", KeyForEdge).KeyType)
+}
+
+func TestGraph_NormalizeWeights(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForEdge, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	n1, err := gr.AddNode(nil, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+	n3, err := gr.AddNode(nil, "#3")
+	require.NoError(t, err)
+
+	e1, err := gr.AddEdge(n1, n2, map[string]interface{}{"weight": 10.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e2, err := gr.AddEdge(n2, n3, map[string]interface{}{"weight": 20.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e3, err := gr.AddEdge(n1, n3, map[string]interface{}{"weight": 30.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e4, err := gr.AddEdge(n3, n1, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	// edge without weight and no default value
+	err = gr.NormalizeWeights("weight", 0, 1, false)
+	assert.Error(t, err)
+
+	err = gr.NormalizeWeights("weight", 0, 1, true)
+	require.NoError(t, err)
+	for e, expected := range map[*Edge]float64{e1: 0, e2: 0.5, e3: 1} {
+		attrs, err := e.GetAttributes()
+		require.NoError(t, err)
+		assert.Equal(t, expected, attrs["weight"], "wrong weight of edge: %s", e.ID)
+	}
+	attrs, err := e4.GetAttributes()
+	require.NoError(t, err)
+	assert.NotContains(t, attrs, "weight")
+
+	// check errors
+	assert.Error(t, gr.NormalizeWeights("weight", 1, 0, true), "wrong range")
+	assert.Error(t, gr.NormalizeWeights("unknown", 0, 1, true), "unknown key")
+	_, err = gml.RegisterKey(KeyForEdge, "count", "", reflect.Int, 1)
+	require.NoError(t, err)
+	assert.Error(t, gr.NormalizeWeights("count", 0, 1, true), "integer key")
+}
+
+func TestGraph_NormalizeWeights_defaultValue(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForEdge, "weight", "", reflect.Float64, 5.0)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	n1, err := gr.AddNode(nil, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+
+	e1, err := gr.AddEdge(n1, n2, map[string]interface{}{"weight": 15.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e2, err := gr.AddEdge(n2, n1, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	err = gr.NormalizeWeights("weight", -1, 1, false)
+	require.NoError(t, err)
+	attrs, err := e1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, attrs["weight"])
+	attrs, err = e2.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, -1.0, attrs["weight"])
+}
+
+func TestGraph_DegreeAssortativity(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("star", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+
+	// the star graph is perfectly disassortative
+	center, err := gr.AddNode(nil, "center")
+	require.NoError(t, err)
+	_, err = gr.DegreeAssortativity()
+	assert.Error(t, err, "no edges")
+	for i := 0; i < 4; i++ {
+		leaf, err := gr.AddNode(nil, "leaf")
+		require.NoError(t, err)
+		_, err = gr.AddEdge(center, leaf, nil, EdgeDirectionDefault, "")
+		require.NoError(t, err)
+	}
+	r, err := gr.DegreeAssortativity()
+	require.NoError(t, err)
+	assert.InDelta(t, -1.0, r, 1e-9)
+
+	// the path graph: degrees pairs (1,2), (2,2), (2,1) in both directions
+	gr, err = gml.AddGraph("path", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	nodes := make([]*Node, 4)
+	for i := range nodes {
+		nodes[i], err = gr.AddNode(nil, "")
+		require.NoError(t, err)
+	}
+	for i := 1; i < len(nodes); i++ {
+		_, err = gr.AddEdge(nodes[i-1], nodes[i], nil, EdgeDirectionDefault, "")
+		require.NoError(t, err)
+	}
+	r, err = gr.DegreeAssortativity()
+	require.NoError(t, err)
+	assert.InDelta(t, -0.5, r, 1e-9)
+
+	// the cycle graph is regular - coefficient is undefined
+	_, err = gr.AddEdge(nodes[3], nodes[0], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.DegreeAssortativity()
+	assert.Error(t, err)
+}
+
+func TestGraph_DirectedDegreeAssortativity(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	nodes := make([]*Node, 4)
+	for i := range nodes {
+		nodes[i], err = gr.AddNode(nil, "")
+		require.NoError(t, err)
+	}
+	// n0 -> n1, n0 -> n2, n0 -> n3, n1 -> n2
+	_, err = gr.AddEdge(nodes[0], nodes[1], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.DirectedDegreeAssortativity()
+	assert.Error(t, err, "single edge")
+	_, err = gr.AddEdge(nodes[0], nodes[2], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(nodes[0], nodes[3], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(nodes[1], nodes[2], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	// pairs of (out-degree of source, in-degree of target): (3,1), (3,2), (3,1), (1,2)
+	r, err := gr.DirectedDegreeAssortativity()
+	require.NoError(t, err)
+	assert.InDelta(t, -1/math.Sqrt(3), r, 1e-9)
+}
+
+func TestGraph_FindCycleEdges(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("dependencies", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, pair := range [][2]string{{"a", "b"}, {"b", "c"}, {"a", "c"}, {"c", "d"}} {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], nil, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+	cycle, err := gr.FindCycleEdges()
+	require.NoError(t, err)
+	assert.Nil(t, cycle)
+	hasCycle, err := gr.HasCycle()
+	require.NoError(t, err)
+	assert.False(t, hasCycle)
+
+	// close the loop b -> c -> d -> b
+	_, err = gr.AddEdgeByID("d", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	cycle, err = gr.FindCycleEdges()
+	require.NoError(t, err)
+	require.Len(t, cycle, 3)
+	assert.Equal(t, []string{"e1", "e3", "e4"}, []string{cycle[0].ID, cycle[1].ID, cycle[2].ID})
+	for i, e := range cycle {
+		assert.Equal(t, e.Target, cycle[(i+1)%len(cycle)].Source)
+	}
+	hasCycle, err = gr.HasCycle()
+	require.NoError(t, err)
+	assert.True(t, hasCycle)
+
+	// self loop
+	gr, err = gml.AddGraph("self loop", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("b", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	cycle, err = gr.FindCycleEdges()
+	require.NoError(t, err)
+	require.Len(t, cycle, 1)
+	assert.Equal(t, "e1", cycle[0].ID)
+}
+
+func TestGraph_FindCycleEdges_Undirected(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("undirected", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("c", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	cycle, err := gr.FindCycleEdges()
+	require.NoError(t, err)
+	assert.Nil(t, cycle, "single edge is not traversed back")
+
+	_, err = gr.AddEdgeByID("a", "c", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	cycle, err = gr.FindCycleEdges()
+	require.NoError(t, err)
+	require.Len(t, cycle, 3)
+	assert.Equal(t, []string{"e0", "e1", "e2"}, []string{cycle[0].ID, cycle[1].ID, cycle[2].ID})
+}
+
+func TestGraph_ConnectedComponents(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("components", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, pair := range [][2]string{{"a", "b"}, {"c", "d"}, {"e", "b"}} {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], nil, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+	_, err = gr.AddNode(nil, "isolated")
+	require.NoError(t, err)
+
+	components := gr.ConnectedComponents()
+	require.Len(t, components, 3)
+	ids := make([][]string, len(components))
+	for i, c := range components {
+		for _, n := range c {
+			ids[i] = append(ids[i], n.ID)
+		}
+	}
+	assert.Equal(t, [][]string{{"a", "b", "e"}, {"c", "d"}, {"n5"}}, ids)
+}
+
+func TestGraph_CanReach(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("reachability", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("b", "c", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("d", "c", nil, EdgeDirectionUndirected, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "isolated")
+	require.NoError(t, err)
+
+	index := gr.NewReachabilityIndex()
+	testCases := []struct {
+		source, target string
+		expected       bool
+	}{
+		{"a", "c", true},
+		{"c", "a", false},
+		{"a", "d", true},
+		{"d", "b", false},
+		{"c", "d", true},
+		{"a", "a", true},
+		{"n4", "a", false},
+		{"a", "n4", false},
+	}
+	for _, tc := range testCases {
+		res, err := gr.CanReach(tc.source, tc.target)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, res, "%s -> %s", tc.source, tc.target)
+		res, err = index.CanReach(tc.source, tc.target)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, res, "index: %s -> %s", tc.source, tc.target)
+	}
+
+	_, err = gr.CanReach("a", "unknown")
+	assert.EqualError(t, err, "node not found: unknown")
+	_, err = index.CanReach("unknown", "a")
+	assert.EqualError(t, err, "node not found: unknown")
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+}
+
+func TestGraph_IsolatedNodes(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("isolated", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, id := range []string{"z", "b", "a", "c", "loop"} {
+		_, err = gr.addNode(id, nil, "")
+		require.NoError(t, err)
+	}
+	_, err = gr.AddEdgeByID("b", "c", nil, EdgeDirectionDefault, "", false)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("loop", "loop", nil, EdgeDirectionDefault, "", false)
+	require.NoError(t, err)
+
+	isolated := gr.IsolatedNodes()
+	require.Len(t, isolated, 2)
+	assert.Equal(t, "a", isolated[0].ID)
+	assert.Equal(t, "z", isolated[1].ID)
+
+	gr, err = gml.AddGraph("empty", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	assert.Empty(t, gr.IsolatedNodes())
+}
+
+func TestGraph_DegreeDistribution(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("star", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, pair := range [][2]string{{"hub", "a"}, {"hub", "b"}, {"hub", "c"}, {"a", "b"}, {"c", "c"}} {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], nil, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+	_, err = gr.AddNode(nil, "isolated")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[int]int{0: 1, 2: 2, 3: 2}, gr.DegreeDistribution())
+	assert.Equal(t, map[int]int{0: 2, 1: 1, 2: 2}, gr.InDegreeDistribution())
+	assert.Equal(t, map[int]int{0: 2, 1: 2, 3: 1}, gr.OutDegreeDistribution())
+	assert.Equal(t, []DegreeCount{{0, 1}, {2, 2}, {3, 2}}, SortedDegreeDistribution(gr.DegreeDistribution()))
+}
+
+func TestGraph_Stats(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("star", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, pair := range [][2]string{{"hub", "a"}, {"hub", "b"}, {"hub", "c"}, {"a", "b"}, {"c", "c"}} {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], nil, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+	_, err = gr.AddNode(nil, "isolated")
+	require.NoError(t, err)
+
+	stats := gr.Stats()
+	assert.Equal(t, GraphStats{Nodes: 5, Edges: 5, SelfLoops: 1, MaxDegree: 3, Directed: true}, stats)
+	assert.Equal(t, "nodes: 5, edges: 5, self-loops: 1, max degree: 3, directed: true", stats.String())
+
+	gr, err = gml.AddGraph("empty", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, GraphStats{}, gr.Stats())
+}
@@ -0,0 +1,108 @@
+package graphml
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GraphBuilder The fluent builder of the graph, which lets to refer nodes by the labels assigned by the caller instead
+// of the IDs generated for them. The first error met while building is kept and returned by Build.
+type GraphBuilder struct {
+	gml           *GraphML
+	edgeDirection EdgeDirection
+	// the labels of nodes in order of their definition with the attributes of nodes
+	labels     []string
+	attributes map[string]map[string]interface{}
+	edges      []builderEdge
+	// the nodes created by Build, indexed by their labels
+	nodes map[string]*Node
+	err   error
+}
+
+// builderEdge The edge to be created by GraphBuilder
+type builderEdge struct {
+	source, target string
+	attributes     map[string]interface{}
+}
+
+// NewGraphBuilder creates builder of the new graph of this GraphML with given default edge direction
+func (gml *GraphML) NewGraphBuilder(edgeDirection EdgeDirection) *GraphBuilder {
+	return &GraphBuilder{
+		gml:           gml,
+		edgeDirection: edgeDirection,
+		attributes:    make(map[string]map[string]interface{}),
+	}
+}
+
+// Node defines the node with given label and attributes. Records error if the node with the same label already
+// defined.
+func (b *GraphBuilder) Node(label string, attributes map[string]interface{}) *GraphBuilder {
+	if b.err != nil {
+		return b
+	}
+	if _, ok := b.attributes[label]; ok {
+		b.err = errors.New(fmt.Sprintf("duplicate node label: %s", label))
+		return b
+	}
+	b.labels = append(b.labels, label)
+	b.attributes[label] = attributes
+	return b
+}
+
+// Edge defines the edge with given attributes between the nodes with given labels. The edge has default direction of
+// the graph. Records error if any of the nodes is not defined yet.
+func (b *GraphBuilder) Edge(sourceLabel, targetLabel string, attributes map[string]interface{}) *GraphBuilder {
+	if b.err != nil {
+		return b
+	}
+	for _, label := range []string{sourceLabel, targetLabel} {
+		if _, ok := b.attributes[label]; !ok {
+			b.err = fmt.Errorf("%w: %s", ErrNodeNotFound, label)
+			return b
+		}
+	}
+	b.edges = append(b.edges, builderEdge{source: sourceLabel, target: targetLabel, attributes: attributes})
+	return b
+}
+
+// Build adds the graph with defined nodes and edges to the GraphML. Returns the first error recorded while defining
+// the graph or met while adding its elements, in which case the graph is not added. The keys registered for
+// the attributes of elements added before the error are not rolled back.
+func (b *GraphBuilder) Build() (*Graph, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	gr, err := b.gml.AddGraph("", b.edgeDirection, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.populate(gr); err != nil {
+		_ = b.gml.RemoveGraph(gr.ID)
+		b.nodes = nil
+		return nil, err
+	}
+	return gr, nil
+}
+
+// NodeByLabel returns the node created by Build for given label or nil if not found
+func (b *GraphBuilder) NodeByLabel(label string) *Node {
+	return b.nodes[label]
+}
+
+// populate adds defined nodes and edges to the given graph
+func (b *GraphBuilder) populate(gr *Graph) error {
+	b.nodes = make(map[string]*Node, len(b.labels))
+	for _, label := range b.labels {
+		n, err := gr.AddNode(b.attributes[label], "")
+		if err != nil {
+			return err
+		}
+		b.nodes[label] = n
+	}
+	for _, e := range b.edges {
+		if _, err := gr.AddEdge(b.nodes[e.source], b.nodes[e.target], e.attributes, EdgeDirectionDefault, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
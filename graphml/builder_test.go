@@ -0,0 +1,68 @@
+package graphml
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGraphBuilder_Build(t *testing.T) {
+	gml := NewGraphML("")
+	b := gml.NewGraphBuilder(EdgeDirectionDirected).
+		Node("a", map[string]interface{}{"name": "A"}).
+		Node("b", map[string]interface{}{"name": "B"}).
+		Node("c", nil).
+		Edge("a", "b", map[string]interface{}{"weight": 1.5}).
+		Edge("b", "c", nil)
+	gr, err := b.Build()
+	require.NoError(t, err)
+	require.Len(t, gml.Graphs, 1)
+	assert.Equal(t, gr, gml.Graphs[0])
+	assert.Equal(t, 3, gr.NodeCount())
+	assert.Equal(t, 2, gr.EdgeCount())
+
+	// labels are resolved to the node IDs
+	a, bNode := b.NodeByLabel("a"), b.NodeByLabel("b")
+	require.NotNil(t, a)
+	assert.Equal(t, "n0", a.ID)
+	name, _, err := bNode.GetAttribute("name")
+	require.NoError(t, err)
+	assert.Equal(t, "B", name)
+	edge := gr.GetEdge(a.ID, bNode.ID)
+	require.NotNil(t, edge)
+	weight, err := edge.Weight("weight")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, weight)
+	assert.NotNil(t, gr.GetEdge(bNode.ID, b.NodeByLabel("c").ID))
+	assert.Nil(t, b.NodeByLabel("unknown"))
+}
+
+func TestGraphBuilder_Build_Errors(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.NewGraphBuilder(EdgeDirectionDirected).
+		Node("a", nil).
+		Edge("a", "b", nil).
+		Node("b", nil).
+		Build()
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+	assert.EqualError(t, err, "node not found: b")
+
+	_, err = gml.NewGraphBuilder(EdgeDirectionDirected).Node("a", nil).Node("a", nil).Build()
+	assert.EqualError(t, err, "duplicate node label: a")
+
+	// the graph is not added when elements can not be added
+	_, err = gml.NewGraphBuilder(EdgeDirectionDirected).
+		Node("a", map[string]interface{}{"size": 1}).
+		Node("b", map[string]interface{}{"size": "big"}).
+		Build()
+	assert.Error(t, err)
+	assert.Empty(t, gml.Graphs)
+	// the key registered for the first node is kept
+	assert.Len(t, gml.Keys, 1)
+	assert.NotNil(t, gml.GetKey("size", KeyForNode))
+
+	_, err = gml.NewGraphBuilder(EdgeDirectionDefault).Build()
+	assert.True(t, errors.Is(err, ErrNoEdgeDirection))
+	assert.Empty(t, gml.Graphs)
+}
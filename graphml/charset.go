@@ -0,0 +1,149 @@
+package graphml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The charsets supported for encoding and decoding of GraphML documents
+const (
+	CharsetUTF8    = "UTF-8"
+	CharsetUTF16   = "UTF-16"
+	CharsetUTF16BE = "UTF-16BE"
+	CharsetUTF16LE = "UTF-16LE"
+)
+
+// newCharsetWriter wraps provided Writer into the writer converting UTF-8 output into the given charset. The documents
+// in UTF-16 charset start with the byte order mark and use big-endian byte order.
+func newCharsetWriter(w io.Writer, charset string) (io.Writer, error) {
+	switch strings.ToUpper(charset) {
+	case CharsetUTF8:
+		return w, nil
+	case CharsetUTF16:
+		if _, err := w.Write([]byte{0xFE, 0xFF}); err != nil {
+			return nil, err
+		}
+		return &utf16Writer{w: w, order: binary.BigEndian}, nil
+	case CharsetUTF16BE:
+		return &utf16Writer{w: w, order: binary.BigEndian}, nil
+	case CharsetUTF16LE:
+		return &utf16Writer{w: w, order: binary.LittleEndian}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported charset: %s", charset))
+	}
+}
+
+// utf16Writer The writer converting UTF-8 text into UTF-16 with given byte order
+type utf16Writer struct {
+	w     io.Writer
+	order binary.ByteOrder
+	// the incomplete UTF-8 sequence left from the previous write
+	pending []byte
+}
+
+func (u *utf16Writer) Write(p []byte) (int, error) {
+	text := append(u.pending, p...)
+	buf := make([]byte, 0, len(text)*2)
+	i := 0
+	for i < len(text) {
+		if !utf8.FullRune(text[i:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(text[i:])
+		i += size
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			buf = u.appendUnit(buf, uint16(r1))
+			buf = u.appendUnit(buf, uint16(r2))
+		} else {
+			buf = u.appendUnit(buf, uint16(r))
+		}
+	}
+	u.pending = append([]byte(nil), text[i:]...)
+	if _, err := u.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (u *utf16Writer) appendUnit(buf []byte, unit uint16) []byte {
+	b := make([]byte, 2)
+	u.order.PutUint16(b, unit)
+	return append(buf, b...)
+}
+
+// newCharsetReader detects UTF-16 charset of the document provided by Reader using its byte order mark or the first
+// characters, and wraps it into reader converting the document into UTF-8. Otherwise, the document is read as is.
+func newCharsetReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		_, _ = br.Discard(2)
+		return &utf16Reader{r: br, order: binary.BigEndian}
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		_, _ = br.Discard(2)
+		return &utf16Reader{r: br, order: binary.LittleEndian}
+	case bytes.HasPrefix(head, []byte{0x00, '<', 0x00, '?'}):
+		return &utf16Reader{r: br, order: binary.BigEndian}
+	case bytes.HasPrefix(head, []byte{'<', 0x00, '?', 0x00}):
+		return &utf16Reader{r: br, order: binary.LittleEndian}
+	default:
+		return br
+	}
+}
+
+// charsetReader is the charset reader of XML decoder accepting the UTF-16 charset labels. The UTF-16 documents
+// are already converted into UTF-8 by newCharsetReader.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToUpper(label) {
+	case CharsetUTF16, CharsetUTF16BE, CharsetUTF16LE:
+		return input, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported charset: %s", label))
+	}
+}
+
+// utf16Reader The reader converting UTF-16 text with given byte order into UTF-8
+type utf16Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	// the converted text not yet consumed
+	pending []byte
+	// the error to be returned when pending text is consumed
+	err error
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	unit := make([]byte, 2)
+	for len(u.pending) < len(p) && u.err == nil {
+		if _, err := io.ReadFull(u.r, unit); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = errors.New("truncated UTF-16 text")
+			}
+			u.err = err
+			break
+		}
+		r := rune(u.order.Uint16(unit))
+		if utf16.IsSurrogate(r) {
+			if _, err := io.ReadFull(u.r, unit); err != nil {
+				u.err = errors.New("truncated UTF-16 surrogate pair")
+				break
+			}
+			r = utf16.DecodeRune(r, rune(u.order.Uint16(unit)))
+		}
+		u.pending = append(u.pending, string(r)...)
+	}
+	if len(u.pending) == 0 {
+		return 0, u.err
+	}
+	n := copy(p, u.pending)
+	u.pending = u.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,431 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecodeOptions The options to control decoding of the GraphML document
+type DecodeOptions struct {
+	// If set then the value attribute of the data element is used as its value when the character data of
+	// the element is empty. Some non-conformant producers store data values this way.
+	DataValueAttribute bool
+	// If set then non-standard extensions of GraphML format are rejected, e.g., keys declared for multiple elements
+	// in form for="node edge". Also, the edges referring nodes not found in their graph are rejected.
+	Strict bool
+	// The function providing the writer to stream the value of data element into instead of keeping it in memory,
+	// when the length of value exceeds LargeValueThreshold. The value of such data element is left empty. If function
+	// returns nil writer then the value is kept in memory.
+	LargeValueWriter func(ctx DataContext) (io.Writer, error)
+	// The length of the data value in bytes, exceeding which the value is passed to the LargeValueWriter
+	LargeValueThreshold int
+	// The data types to override the types of decoded keys with, indexed by the attribute names
+	KeyTypeOverrides map[string]DataType
+	// If set then the key with overridden type is reverted to the string type when any of its values, including
+	// the default one, does not fit the overriding type. Otherwise, decoding fails.
+	KeyTypeOverrideFallback bool
+}
+
+// parseEdgeDefault returns the direction of edges defined by the given edge default value of graph and flag to indicate
+// whether the value is supported. The value is accepted regardless of case and surrounding whitespace, the empty value
+// means that the direction of edges is not specified.
+func parseEdgeDefault(value string) (EdgeDirection, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case edgeDirectionDirected:
+		return EdgeDirectionDirected, true
+	case edgeDirectionUndirected:
+		return EdgeDirectionUndirected, true
+	case "":
+		return EdgeDirectionDefault, true
+	default:
+		return EdgeDirectionDefault, false
+	}
+}
+
+// DataContext The context of the data element being decoded
+type DataContext struct {
+	// The ID of the key of data
+	Key string
+	// The type of element holding the data
+	Element KeyForElement
+	// The ID of graph holding the element, empty for the data of GraphML
+	GraphID string
+	// The ID of element holding the data, equal to GraphID for the data of the graph and empty for the data of GraphML
+	ElementID string
+}
+
+// DecodeWithOptions decodes GraphML from provided Reader according to the given options. The documents in UTF-16
+// charset are detected by their byte order mark or the first characters. The documents with duplicate IDs of keys,
+// or duplicate IDs of nodes or edges within the same graph are rejected.
+func (gml *GraphML) DecodeWithOptions(r io.Reader, options DecodeOptions) error {
+	dec := xml.NewDecoder(newCharsetReader(r))
+	dec.CharsetReader = charsetReader
+	if options.LargeValueWriter != nil {
+		dec = xml.NewTokenDecoder(&largeValueReader{dec: dec, options: options})
+	}
+	err := dec.Decode(gml)
+	if err != nil {
+		return err
+	}
+
+	// check edge directions of all graphs before populating anything
+	for _, gr := range gml.allGraphs() {
+		if _, ok := parseEdgeDefault(gr.EdgeDefault); !ok {
+			return errors.New(fmt.Sprintf("unsupported edge default direction: %s in graph: %s", gr.EdgeDefault, gr.ID))
+		}
+	}
+
+	// populate auxiliary data structure
+	keyIDs := make(map[string]bool, len(gml.Keys))
+	for _, key := range gml.Keys {
+		if keyIDs[key.ID] {
+			return errors.New(fmt.Sprintf("duplicate key ID: %s", key.ID))
+		}
+		keyIDs[key.ID] = true
+		if key.KeyType == "" {
+			key.KeyType = gml.keyTypeDefault
+		}
+		if key.Target == "" {
+			key.Target = KeyForAll
+		}
+		if keyType, ok := options.KeyTypeOverrides[key.Name]; ok {
+			key.KeyType = keyType
+		}
+		if targets := parseKeyTargets(string(key.Target)); len(targets) > 1 {
+			if options.Strict {
+				return errors.New(fmt.Sprintf("key: %s declared for multiple elements: %s", key.ID, key.Target))
+			}
+			key.targets = targets
+		}
+		for _, target := range key.targetList() {
+			gml.keysByIdentifier[keyIdentifier(key.Name, target)] = key
+		}
+		gml.keysById[key.ID] = key
+	}
+
+	for _, gr := range gml.allGraphs() {
+		gml.linkGraph(gr)
+		gr.edgesDirection, _ = parseEdgeDefault(gr.EdgeDefault)
+		gr.EdgeDefault = strings.ToLower(strings.TrimSpace(gr.EdgeDefault))
+		// the empty graph is initialized the same way as created by AddGraph
+		if gr.Nodes == nil {
+			gr.Nodes = make([]*Node, 0)
+		}
+		if gr.Edges == nil {
+			gr.Edges = make([]*Edge, 0)
+		}
+		// populate edges map and link them to their graph
+		gr.edgesMap = make(map[string]*Edge)
+		gr.edgesById = make(map[string]*Edge)
+		for _, e := range gr.Edges {
+			if _, ok := gr.edgesById[e.ID]; ok && e.ID != "" {
+				return errors.New(fmt.Sprintf("duplicate edge ID: %s in graph: %s", e.ID, gr.ID))
+			}
+			gr.linkEdge(e)
+		}
+		// populate nodes map and link them to their graph
+		gr.nodesMap = make(map[string]*Node)
+		for _, n := range gr.Nodes {
+			if _, ok := gr.nodesMap[n.ID]; ok {
+				return errors.New(fmt.Sprintf("duplicate node ID: %s in graph: %s", n.ID, gr.ID))
+			}
+			gr.linkNode(n)
+			for _, nested := range n.Graphs {
+				nested.parentNode = n
+			}
+		}
+		if options.Strict {
+			if err = gr.checkEdgeEndpoints(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if options.DataValueAttribute {
+		gml.forEachData(func(d *Data) {
+			if strings.TrimSpace(d.Value) == "" && d.valueAttr != "" {
+				d.Value = d.valueAttr
+			}
+		})
+	}
+	if len(options.KeyTypeOverrides) > 0 {
+		if err = gml.checkKeyTypeOverrides(options); err != nil {
+			return err
+		}
+	}
+	gml.loadTimestamps()
+
+	return err
+}
+
+// checkEdgeEndpoints checks that source and target nodes of all edges exist in this graph
+func (gr *Graph) checkEdgeEndpoints() error {
+	for _, e := range gr.Edges {
+		if gr.GetNode(e.Source) == nil {
+			return errors.New(fmt.Sprintf("source node: %s of edge: %s not found in graph: %s", e.Source, e.ID, gr.ID))
+		}
+		if gr.GetNode(e.Target) == nil {
+			return errors.New(fmt.Sprintf("target node: %s of edge: %s not found in graph: %s", e.Target, e.ID, gr.ID))
+		}
+	}
+	return nil
+}
+
+// checkKeyTypeOverrides checks that values of the keys with overridden types fit these types. The key with values
+// not fitting its type is either reverted to the string type or error returned depending on provided options.
+func (gml *GraphML) checkKeyTypeOverrides(options DecodeOptions) error {
+	fits := func(key *Key, value string) bool {
+		if value == "" {
+			return true
+		}
+		_, err := gml.parseValue(value, key.KeyType)
+		return err == nil
+	}
+	invalid := make(map[string]string)
+	for _, key := range gml.Keys {
+		if _, ok := options.KeyTypeOverrides[key.Name]; ok && !fits(key, key.DefaultValue) {
+			invalid[key.ID] = key.DefaultValue
+		}
+	}
+	gml.forEachData(func(d *Data) {
+		key, ok := gml.keysById[d.Key]
+		if !ok {
+			return
+		}
+		if _, overridden := options.KeyTypeOverrides[key.Name]; overridden && !fits(key, d.Value) {
+			if _, found := invalid[key.ID]; !found {
+				invalid[key.ID] = d.Value
+			}
+		}
+	})
+	for _, key := range gml.Keys {
+		value, ok := invalid[key.ID]
+		if !ok {
+			continue
+		}
+		if !options.KeyTypeOverrideFallback {
+			return errors.New(fmt.Sprintf("value: %s of key: %s does not fit overriding type: %s", value, key.ID, key.KeyType))
+		}
+		key.KeyType = StringType
+	}
+	return nil
+}
+
+// UnmarshalXML decodes root element keeping its attributes not defined by GraphML in ExtraAttrs. The names of such
+// attributes are qualified by the prefixes declared within root element.
+func (gml *GraphML) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	type graphML GraphML
+	if err := dec.DecodeElement((*graphML)(gml), &start); err != nil {
+		return err
+	}
+	prefixes := make(map[string]string)
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" {
+			prefixes[attr.Value] = attr.Name.Local
+		}
+	}
+	for _, attr := range start.Attr {
+		name := attr.Name.Local
+		if attr.Name.Space != "" {
+			prefix, ok := prefixes[attr.Name.Space]
+			if !ok {
+				prefix = attr.Name.Space
+			}
+			name = prefix + ":" + name
+		}
+		switch name {
+		case "xmlns":
+			// decoded into XmlNS
+		case "xmlns:xsi":
+			gml.XmlnsXsi = attr.Value
+		case "xsi:schemaLocation":
+			gml.XsiSchemaLocation = attr.Value
+		default:
+			if gml.ExtraAttrs == nil {
+				gml.ExtraAttrs = make(map[string]string)
+			}
+			gml.ExtraAttrs[name] = attr.Value
+		}
+	}
+	return nil
+}
+
+// UnmarshalXML decodes locator element accepting its href attribute either qualified by the XLink namespace or not
+func (l *Locator) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "href" {
+			l.Href = attr.Value
+		}
+	}
+	return dec.Skip()
+}
+
+// UnmarshalXML decodes data element keeping its value attribute if present, and the raw XML of its content if it holds
+// nested XML elements
+func (d *Data) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var data struct {
+		ID       string `xml:"id,attr,omitempty"`
+		Key      string `xml:"key,attr"`
+		Value    string `xml:",chardata"`
+		InnerXML string `xml:",innerxml"`
+	}
+	if err := dec.DecodeElement(&data, &start); err != nil {
+		return err
+	}
+	d.ID, d.Key, d.Value = data.ID, data.Key, data.Value
+	if isComplexXML(data.InnerXML) {
+		d.complexXML, d.complexFrom = data.InnerXML, data.Value
+	}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == "value" {
+			d.valueAttr = attr.Value
+		}
+	}
+	return nil
+}
+
+// isComplexXML checks if provided raw XML content holds nested XML elements, i.e., anything but character data, CDATA
+// sections, and comments
+func isComplexXML(content string) bool {
+	for {
+		i := strings.Index(content, "<")
+		if i < 0 {
+			return false
+		}
+		content = content[i:]
+		var end string
+		switch {
+		case strings.HasPrefix(content, "<![CDATA["):
+			end = "]]>"
+		case strings.HasPrefix(content, "<!--"):
+			end = "-->"
+		default:
+			return true
+		}
+		j := strings.Index(content, end)
+		if j < 0 {
+			return false
+		}
+		content = content[j+len(end):]
+	}
+}
+
+// largeValueReader The reader of XML tokens which streams large values of data elements into the writers provided
+// by DecodeOptions.LargeValueWriter instead of passing them to the decoder
+type largeValueReader struct {
+	dec     *xml.Decoder
+	options DecodeOptions
+	// the stack of graph, node, and edge elements enclosing the current token
+	elements []DataContext
+	// the context of the current data element, nil if outside of data element
+	data *DataContext
+	// the buffered value of the current data element
+	value []byte
+	// the writer to stream the value of the current data element into, nil if value is buffered
+	w io.Writer
+	// the flag to indicate that the value of the current data element is kept in memory regardless of its length
+	keep bool
+	// the tokens to be returned before reading next
+	pending []xml.Token
+}
+
+func (r *largeValueReader) Token() (xml.Token, error) {
+	if len(r.pending) > 0 {
+		t := r.pending[0]
+		r.pending = r.pending[1:]
+		return t, nil
+	}
+	for {
+		t, err := r.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := t.(type) {
+		case xml.StartElement:
+			r.startElement(token)
+		case xml.EndElement:
+			if r.data != nil && token.Name.Local == "data" {
+				return r.endData(token)
+			}
+			if local := token.Name.Local; local == "graph" || local == "node" || local == "edge" {
+				r.elements = r.elements[:len(r.elements)-1]
+			}
+		case xml.CharData:
+			if r.data != nil {
+				if err = r.writeValue(token); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		return xml.CopyToken(t), nil
+	}
+}
+
+// startElement tracks the context of provided start element
+func (r *largeValueReader) startElement(start xml.StartElement) {
+	idAttr := "id"
+	if start.Name.Local == "data" {
+		idAttr = "key"
+	}
+	var id string
+	for _, attr := range start.Attr {
+		if attr.Name.Local == idAttr {
+			id = attr.Value
+		}
+	}
+	switch start.Name.Local {
+	case "graph":
+		r.elements = append(r.elements, DataContext{Element: KeyForGraph, GraphID: id, ElementID: id})
+	case "node", "edge":
+		ctx := DataContext{Element: KeyForElement(start.Name.Local), ElementID: id}
+		if len(r.elements) > 0 {
+			ctx.GraphID = r.elements[len(r.elements)-1].GraphID
+		}
+		r.elements = append(r.elements, ctx)
+	case "data":
+		ctx := DataContext{Element: KeyForGraphML}
+		if len(r.elements) > 0 {
+			ctx = r.elements[len(r.elements)-1]
+		}
+		ctx.Key = id
+		r.data = &ctx
+		r.value = r.value[:0]
+		r.w = nil
+		r.keep = false
+	}
+}
+
+// writeValue buffers provided character data of the current data element or writes it into the stream
+func (r *largeValueReader) writeValue(text xml.CharData) (err error) {
+	if r.w != nil {
+		_, err = r.w.Write(text)
+		return err
+	}
+	r.value = append(r.value, text...)
+	if r.keep || len(r.value) <= r.options.LargeValueThreshold {
+		return nil
+	}
+	if r.w, err = r.options.LargeValueWriter(*r.data); err != nil {
+		return err
+	} else if r.w == nil {
+		r.keep = true
+		return nil
+	}
+	_, err = r.w.Write(r.value)
+	r.value = r.value[:0]
+	return err
+}
+
+// endData completes the current data element returning its buffered value followed by the end element
+func (r *largeValueReader) endData(end xml.EndElement) (xml.Token, error) {
+	r.data = nil
+	if r.w != nil || len(r.value) == 0 {
+		return end, nil
+	}
+	r.pending = append(r.pending, end)
+	return xml.CharData(append([]byte(nil), r.value...)), nil
+}
@@ -0,0 +1,560 @@
+package graphml
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGraphML_DecodeWithOptions_DataValueAttribute(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_data_value_attribute.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.DecodeWithOptions(graphFile, DecodeOptions{DataValueAttribute: true})
+	require.NoError(t, err, "failed to decode")
+
+	require.Len(t, gml.Graphs, 1)
+	require.Len(t, gml.Graphs[0].Nodes, 2)
+	attrs, err := gml.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "first", "weight": 1.5}, attrs)
+	attrs, err = gml.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "second", "weight": 2.5}, attrs)
+
+	// check that value attribute is ignored by default
+	graphFile, err = os.Open("../data/test_graph_data_value_attribute.xml")
+	require.NoError(t, err, "failed to open file")
+	gml = NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	assert.Equal(t, "", gml.Graphs[0].Nodes[0].Data[0].Value)
+	attrs, err = gml.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "second", "weight": 2.5}, attrs)
+}
+
+func TestGraphML_Decode_MultiTargetKey(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_multi_target_key.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+
+	assert.Equal(t, gml.Keys[0], gml.GetKey("color", KeyForNode))
+	assert.Equal(t, gml.Keys[0], gml.GetKey("color", KeyForEdge))
+	assert.Nil(t, gml.GetKey("color", KeyForGraph))
+	assert.Equal(t, gml.Keys[1], gml.GetKey("weight", KeyForGraph))
+	assert.Equal(t, gml.Keys[1], gml.GetKey("weight", KeyForNode))
+
+	gr := gml.Graphs[0]
+	attrs, err := gr.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 0.5}, attrs)
+	attrs, err = gr.Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red", "weight": 1.5}, attrs)
+	attrs, err = gr.Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "black"}, attrs)
+	attrs, err = gr.Edges[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "blue"}, attrs)
+
+	// remove key for all its targets
+	err = gml.RemoveKey(gml.Keys[0])
+	require.NoError(t, err)
+	assert.Nil(t, gml.GetKey("color", KeyForEdge))
+	assert.Len(t, gr.Nodes[0].Data, 1)
+	assert.Len(t, gr.Edges[0].Data, 0)
+
+	// strict mode
+	graphFile, err = os.Open("../data/test_graph_multi_target_key.xml")
+	require.NoError(t, err, "failed to open file")
+	gml = NewGraphML("")
+	err = gml.DecodeWithOptions(graphFile, DecodeOptions{Strict: true})
+	assert.EqualError(t, err, "key: d0 declared for multiple elements: node edge")
+}
+
+func TestGraphML_DecodeWithOptions_LargeValueWriter(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("documents", EdgeDirectionDirected, map[string]interface{}{"text": strings.Repeat("graph ", 10)})
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"text": "short"}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"text": strings.Repeat("<node> & ", 100)}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"text": strings.Repeat("kept ", 100)}, "")
+	require.NoError(t, err)
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, true)
+	require.NoError(t, err)
+
+	streams := make(map[string]*bytes.Buffer)
+	contexts := make([]DataContext, 0)
+	options := DecodeOptions{
+		LargeValueThreshold: 32,
+		LargeValueWriter: func(ctx DataContext) (io.Writer, error) {
+			contexts = append(contexts, ctx)
+			if ctx.ElementID == "n2" {
+				return nil, nil
+			}
+			buf := &bytes.Buffer{}
+			streams[ctx.ElementID] = buf
+			return buf, nil
+		},
+	}
+	decoded := NewGraphML("")
+	err = decoded.DecodeWithOptions(bytes.NewReader(outBuf.Bytes()), options)
+	require.NoError(t, err)
+
+	assert.Equal(t, []DataContext{
+		{Key: "d1", Element: KeyForNode, GraphID: "g0", ElementID: "n1"},
+		{Key: "d1", Element: KeyForNode, GraphID: "g0", ElementID: "n2"},
+		{Key: "d0", Element: KeyForGraph, GraphID: "g0", ElementID: "g0"},
+	}, contexts)
+	assert.Equal(t, strings.Repeat("<node> & ", 100), streams["n1"].String())
+	assert.Equal(t, strings.Repeat("graph ", 10), streams["g0"].String())
+
+	nodes := decoded.Graphs[0].Nodes
+	require.Len(t, nodes, 3)
+	assert.Equal(t, "short", nodes[0].Data[0].Value)
+	assert.Equal(t, "", nodes[1].Data[0].Value)
+	assert.Equal(t, strings.Repeat("kept ", 100), nodes[2].Data[0].Value)
+	assert.Equal(t, "", decoded.Graphs[0].Data[0].Value)
+
+	// writer error
+	options.LargeValueWriter = func(ctx DataContext) (io.Writer, error) {
+		return nil, errors.New("no space left")
+	}
+	err = NewGraphML("").DecodeWithOptions(bytes.NewReader(outBuf.Bytes()), options)
+	assert.EqualError(t, err, "no space left")
+}
+
+func TestGraphML_Decode_DuplicateIDs(t *testing.T) {
+	documents := map[string]string{
+		"duplicate key ID: d0": `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<key id="d0" for="node" attr.name="color" attr.type="string"/>
+	<key id="d0" for="edge" attr.name="weight" attr.type="double"/>
+</graphml>`,
+		"duplicate node ID: n0 in graph: g0": `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"/><node id="n1"/><node id="n0"/>
+	</graph>
+</graphml>`,
+		"duplicate edge ID: e0 in graph: g0": `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"/><node id="n1"/>
+		<edge id="e0" source="n0" target="n1"/><edge id="e0" source="n1" target="n0"/>
+	</graph>
+</graphml>`,
+	}
+	for expected, document := range documents {
+		err := NewGraphML("").Decode(strings.NewReader(document))
+		assert.EqualError(t, err, expected)
+	}
+
+	// the same IDs in different graphs and edges without IDs are allowed
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"/><node id="n1"/>
+		<edge source="n0" target="n1"/><edge source="n1" target="n0"/>
+	</graph>
+	<graph id="g1" edgedefault="directed">
+		<node id="n0"/>
+	</graph>
+</graphml>`
+	err := NewGraphML("").Decode(strings.NewReader(document))
+	assert.NoError(t, err)
+}
+
+func TestGraphML_DecodeWithOptions_StrictEdgeEndpoints(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"/><node id="n1"/>
+		<edge id="e0" source="n0" target="n1"/>
+		<edge id="e1" source="n1" target="n2"/>
+	</graph>
+</graphml>`
+	gml := NewGraphML("")
+	err := gml.DecodeWithOptions(strings.NewReader(document), DecodeOptions{Strict: true})
+	assert.EqualError(t, err, "target node: n2 of edge: e1 not found in graph: g0")
+
+	// lax by default
+	gml = NewGraphML("")
+	err = gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+	assert.Nil(t, gml.Graphs[0].Edges[1].TargetNode())
+}
+
+func TestGraphML_DecodeWithOptions_KeyTypeOverrides(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<key id="d0" for="node" attr.name="count" attr.type="double"/>
+	<key id="d1" for="node" attr.name="code" attr.type="int"/>
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"><data key="d0">3</data><data key="d1">42</data></node>
+		<node id="n1"><data key="d0">5</data><data key="d1">x42</data></node>
+	</graph>
+</graphml>`
+	overrides := map[string]DataType{"count": IntType, "code": LongType}
+
+	gml := NewGraphML("")
+	err := gml.DecodeWithOptions(strings.NewReader(document), DecodeOptions{KeyTypeOverrides: overrides})
+	assert.EqualError(t, err, "value: x42 of key: d1 does not fit overriding type: long")
+
+	gml = NewGraphML("")
+	err = gml.DecodeWithOptions(strings.NewReader(document),
+		DecodeOptions{KeyTypeOverrides: overrides, KeyTypeOverrideFallback: true})
+	require.NoError(t, err)
+	assert.Equal(t, IntType, gml.GetKey("count", KeyForNode).KeyType)
+	assert.Equal(t, StringType, gml.GetKey("code", KeyForNode).KeyType)
+	attrs, err := gml.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": 3, "code": "42"}, attrs)
+	attrs, err = gml.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": 5, "code": "x42"}, attrs)
+}
+
+func TestGraphML_Decode_ExtraAttrs(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" version="2.1"
+	xmlns:y="http://www.yworks.com/xml/graphml" y:tool="yEd"
+	xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+	xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns graphml.xsd">
+	<graph id="g0" edgedefault="directed"/>
+</graphml>`
+
+	gml := NewGraphML("")
+	err := gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+	expected := map[string]string{
+		"version": "2.1",
+		"xmlns:y": "http://www.yworks.com/xml/graphml",
+		"y:tool":  "yEd",
+	}
+	assert.Equal(t, expected, gml.ExtraAttrs)
+	assert.Equal(t, "http://graphml.graphdrawing.org/xmlns graphml.xsd", gml.XsiSchemaLocation)
+
+	// check that attributes survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), ` version="2.1" xmlns:y="http://www.yworks.com/xml/graphml" y:tool="yEd">`)
+
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded.ExtraAttrs)
+}
+
+func TestGraphML_Decode_LenientParsing(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_complex_data.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	n0, n1 := gml.Graphs[0].GetNode("n0"), gml.Graphs[0].GetNode("n1")
+
+	_, err = n0.GetAttributes()
+	assert.Error(t, err)
+
+	gml.LenientParsing = true
+	attrs, err := n0.GetAttributes()
+	require.NoError(t, err)
+	expected := map[string]interface{}{
+		"d0":     `<y:ShapeNode><y:Fill color="#FFCC00"/></y:ShapeNode>`,
+		"weight": "heavy",
+		"label":  "<first>",
+	}
+	assert.Equal(t, expected, attrs)
+	attrs, err = n1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"d0": "", "weight": 2, "label": ""}, attrs)
+
+	// check that complex value survives round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `<data key="d0"><y:ShapeNode><y:Fill color="#FFCC00"/></y:ShapeNode></data>`)
+	decoded := NewGraphML("")
+	decoded.LenientParsing = true
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err = decoded.Graphs[0].GetNode("n0").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, expected, attrs)
+
+	// the changed value is not complex anymore
+	n0.Data[0].Value = "plain"
+	attrs, err = n0.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "plain", attrs["d0"])
+}
+
+func TestGraphML_Decode_LenientParsing_UnnamedKeys(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+    <key id="d0" for="node" yfiles.type="nodegraphics"/>
+    <key id="d1" for="node" yfiles.type="resources"/>
+    <graph id="G" edgedefault="directed">
+        <node id="n0">
+            <data key="d0"><a>1</a></data>
+            <data key="d1"><b>2</b></data>
+        </node>
+    </graph>
+</graphml>`
+	gml := NewGraphML("")
+	gml.LenientParsing = true
+	err := gml.Decode(strings.NewReader(doc))
+	require.NoError(t, err, "failed to decode")
+	n0 := gml.Graphs[0].GetNode("n0")
+
+	attrs, err := n0.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"d0": "<a>1</a>", "d1": "<b>2</b>"}, attrs)
+	assert.Equal(t, []string{"d0", "d1"}, gml.OrderedAttributeNames(KeyForNode))
+	assert.Equal(t, []string{"d0", "d1"}, gml.AttributeNamesForTarget(KeyForNode))
+
+	value, ok, err := n0.GetAttribute("d1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "<b>2</b>", value)
+
+	views, err := n0.AttributesWithKeys()
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, "d0", views[0].Key.ID)
+	assert.Equal(t, "d1", views[1].Key.ID)
+}
+
+func TestGraphML_Decode_RoundTrip(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err)
+	assertRoundTrip(t, gml)
+
+	// optional attributes and explicit edge directions
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<key id="weight" for="edge" attr.name="weight" attr.type="double"><default>1</default></key>
+	<graph edgedefault="undirected">
+		<node id="a"/><node id="b"/>
+		<edge source="a" target="b" directed="true"/>
+		<edge id="custom" source="b" target="a"><data key="weight">2.5</data></edge>
+	</graph>
+</graphml>`
+	gml = NewGraphML("")
+	err = gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+	assertRoundTrip(t, gml)
+}
+
+// assertRoundTrip checks that given GraphML is encoded and decoded back without loss of its structure
+func assertRoundTrip(t *testing.T, gml *GraphML) {
+	outBuf := &bytes.Buffer{}
+	err := gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	encoded := outBuf.String()
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+
+	assert.Equal(t, gml.Description, decoded.Description)
+	assert.Equal(t, gml.Keys, decoded.Keys)
+	assert.Equal(t, dataValues(gml.Data), dataValues(decoded.Data))
+	require.Len(t, decoded.Graphs, len(gml.Graphs))
+	for i, gr := range gml.Graphs {
+		actual := decoded.Graphs[i]
+		assert.Equal(t, gr.ID, actual.ID)
+		assert.Equal(t, gr.EdgeDefault, actual.EdgeDefault)
+		assert.Equal(t, gr.Description, actual.Description)
+		assert.Equal(t, dataValues(gr.Data), dataValues(actual.Data))
+		require.Len(t, actual.Nodes, len(gr.Nodes))
+		for j, n := range gr.Nodes {
+			assert.Equal(t, n.ID, actual.Nodes[j].ID)
+			assert.Equal(t, n.Description, actual.Nodes[j].Description)
+			assert.Equal(t, dataValues(n.Data), dataValues(actual.Nodes[j].Data), "data of node: %s", n.ID)
+		}
+		require.Len(t, actual.Edges, len(gr.Edges))
+		for j, e := range gr.Edges {
+			assert.Equal(t, e.ID, actual.Edges[j].ID)
+			assert.Equal(t, e.Source, actual.Edges[j].Source)
+			assert.Equal(t, e.Target, actual.Edges[j].Target)
+			assert.Equal(t, e.Directed, actual.Edges[j].Directed)
+			assert.Equal(t, e.Direction(), actual.Edges[j].Direction())
+			assert.Equal(t, e.Description, actual.Edges[j].Description)
+			assert.Equal(t, dataValues(e.Data), dataValues(actual.Edges[j].Data), "data of edge: %s", e.ID)
+		}
+	}
+
+	// encoded identically
+	outBuf.Reset()
+	err = decoded.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, outBuf.String())
+}
+
+// dataValues returns values of given data indexed by their keys
+func dataValues(data []*Data) map[string]string {
+	res := make(map[string]string, len(data))
+	for _, d := range data {
+		res[d.Key] = d.Value
+	}
+	return res
+}
+
+func TestGraphML_Decode_EmptyGraph(t *testing.T) {
+	created := NewGraphML("")
+	_, err := created.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(strings.NewReader(`<graphml><graph id="g0" edgedefault="directed"/></graphml>`))
+	require.NoError(t, err)
+
+	for _, gml := range []*GraphML{created, decoded} {
+		gr := gml.Graphs[0]
+		assert.Equal(t, []*Node{}, gr.Nodes)
+		assert.Equal(t, []*Edge{}, gr.Edges)
+		assert.Equal(t, 0, gr.NodeCount())
+		assert.Equal(t, 0, gr.EdgeCount())
+		assert.Nil(t, gr.GetNode("n0"))
+		assert.Nil(t, gr.GetEdge("n0", "n1"))
+		assert.Nil(t, gr.GetEdgeByID("e0"))
+		assert.Empty(t, gr.GetEdges("n0", "n1"))
+		assert.True(t, errors.Is(gr.RemoveNode("n0"), ErrNodeNotFound))
+
+		hasCycle, err := gr.HasCycle()
+		require.NoError(t, err)
+		assert.False(t, hasCycle)
+		cycle, err := gr.FindCycleEdges()
+		require.NoError(t, err)
+		assert.Empty(t, cycle)
+		assert.Empty(t, gr.ConnectedComponents())
+		assert.Empty(t, gr.IsolatedNodes())
+		assert.Empty(t, gr.DegreeDistribution())
+		assert.Empty(t, gr.InDegreeDistribution())
+		assert.Empty(t, gr.OutDegreeDistribution())
+		_, err = gr.DegreeAssortativity()
+		assert.Error(t, err)
+		_, err = gr.CanReach("n0", "n1")
+		assert.True(t, errors.Is(err, ErrNodeNotFound))
+		_, err = gr.NewReachabilityIndex().CanReach("n0", "n1")
+		assert.True(t, errors.Is(err, ErrNodeNotFound))
+		assert.Error(t, gr.NormalizeWeights("weight", 0, 1, true))
+
+		filtered, err := gr.FilterSubgraph(nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, filtered.Nodes)
+		reversed, err := gr.Reversed()
+		require.NoError(t, err)
+		assert.Empty(t, reversed.Edges)
+		reduced, err := gr.TransitiveReduction()
+		require.NoError(t, err)
+		assert.Empty(t, reduced.Edges)
+
+		jsonData, err := gml.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"nodes":[],"edges":[]`)
+		assertRoundTrip(t, gml)
+	}
+	assert.Equal(t, created.Graphs[0].Nodes, decoded.Graphs[0].Nodes)
+	assert.Equal(t, created.Graphs[0].Edges, decoded.Graphs[0].Edges)
+}
+
+func TestGraphML_Decode_EdgeDefault(t *testing.T) {
+	for _, c := range []struct {
+		value     string
+		direction EdgeDirection
+		expected  string
+	}{
+		{"directed", EdgeDirectionDirected, "directed"},
+		{"Directed", EdgeDirectionDirected, "directed"},
+		{" undirected ", EdgeDirectionUndirected, "undirected"},
+		{"UNDIRECTED\n", EdgeDirectionUndirected, "undirected"},
+	} {
+		gml := NewGraphML("")
+		err := gml.DecodeString(`<graphml><graph id="g0" edgedefault="` + c.value + `"><node id="n0"/><node id="n1"/><edge source="n0" target="n1"/></graph></graphml>`)
+		require.NoError(t, err, c.value)
+		gr := gml.Graphs[0]
+		assert.Equal(t, c.direction, gr.edgesDirection, c.value)
+		assert.Equal(t, c.expected, gr.EdgeDefault, c.value)
+		assert.Equal(t, c.direction, gr.Edges[0].Direction(), c.value)
+	}
+
+	gml := NewGraphML("")
+	err := gml.DecodeString(`<graphml><graph id="g0" edgedefault="bidirectional"/></graphml>`)
+	assert.EqualError(t, err, "unsupported edge default direction: bidirectional in graph: g0")
+
+	// the wrong value is reported as is before anything populated
+	gml = NewGraphML("")
+	err = gml.DecodeString(`<graphml><key id="d0" for="node" attr.name="weight" attr.type="int"/>` +
+		`<graph id="g0" edgedefault=" Directed "/><graph id="g1" edgedefault=" BiDirected "/></graphml>`)
+	assert.EqualError(t, err, "unsupported edge default direction:  BiDirected  in graph: g1")
+	assert.Equal(t, " Directed ", gml.Graphs[0].EdgeDefault)
+	assert.Equal(t, EdgeDirectionDefault, gml.Graphs[0].edgesDirection)
+	assert.Nil(t, gml.GetGraph("g0"))
+	assert.Nil(t, gml.GetKey("weight", KeyForNode))
+}
+
+func TestGraphML_Decode_Locator(t *testing.T) {
+	const doc = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xlink="http://www.w3.org/1999/xlink">
+<graph id="g0" edgedefault="directed">
+<node id="n0"><locator xlink:href="part1.graphml#g0"/></node>
+<node id="n1"/>
+</graph>
+<graph id="g1" edgedefault="directed"><locator xlink:href="part2.graphml"/></graph>
+</graphml>`
+	gml := NewGraphML("")
+	err := gml.DecodeString(doc)
+	require.NoError(t, err)
+	require.NotNil(t, gml.Graphs[0].Nodes[0].Locator)
+	assert.Equal(t, "part1.graphml#g0", gml.Graphs[0].Nodes[0].Locator.Href)
+	assert.Nil(t, gml.Graphs[0].Nodes[1].Locator)
+	assert.Nil(t, gml.Graphs[0].Locator)
+	require.NotNil(t, gml.Graphs[1].Locator)
+	assert.Equal(t, "part2.graphml", gml.Graphs[1].Locator.Href)
+	filtered, err := gml.Graphs[0].FilterSubgraph(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, gml.Graphs[0].Nodes[0].Locator, filtered.Nodes[0].Locator)
+	assert.NotSame(t, gml.Graphs[0].Nodes[0].Locator, filtered.Nodes[0].Locator)
+
+	// check that locators survive round-trip
+	str, err := gml.EncodeToString(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(str, `xmlns:xlink="http://www.w3.org/1999/xlink"`))
+	assert.Contains(t, str, `<node id="n0"><locator xlink:href="part1.graphml#g0"></locator></node>`)
+	assert.Contains(t, str, `<graph id="g1" edgedefault="directed"><locator xlink:href="part2.graphml"></locator></graph>`)
+	decoded := NewGraphML("")
+	err = decoded.DecodeString(str)
+	require.NoError(t, err)
+	assert.Equal(t, gml.Graphs[0].Nodes[0].Locator, decoded.Graphs[0].Nodes[0].Locator)
+	assert.Equal(t, gml.Graphs[1].Locator, decoded.Graphs[1].Locator)
+
+	// the namespace is declared for locators created with API
+	created := NewGraphML("")
+	gr, err := created.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	str, err = created.EncodeToString(false)
+	require.NoError(t, err)
+	assert.NotContains(t, str, "xlink")
+	gr.Locator = &Locator{Href: "external.graphml"}
+	str, err = created.EncodeToString(false)
+	require.NoError(t, err)
+	assert.Contains(t, str, `xmlns:xlink="http://www.w3.org/1999/xlink"`)
+	decoded = NewGraphML("")
+	err = decoded.DecodeString(str)
+	require.NoError(t, err)
+	assert.Equal(t, &Locator{Href: "external.graphml"}, decoded.Graphs[0].Locator)
+
+	// the locator with undeclared prefix is accepted
+	decoded = NewGraphML("")
+	err = decoded.DecodeString(`<graphml><graph id="g0"><locator xlink:href="other.graphml"/></graph></graphml>`)
+	require.NoError(t, err)
+	assert.Equal(t, &Locator{Href: "other.graphml"}, decoded.Graphs[0].Locator)
+}
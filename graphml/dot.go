@@ -0,0 +1,120 @@
+package graphml
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteDOT writes graphs of this GraphML into the provided Writer using DOT language of Graphviz. Each graph is
+// written as separate "digraph" block, or "graph" block if the graph and all its edges are undirected. The undirected
+// edges within "digraph" block get "dir=none" attribute. The descriptions of elements are written as labels, the nodes
+// without description are labeled by their IDs. The data attributes of elements are written as DOT attributes.
+// The graphs nested into nodes are written as separate blocks following the graph holding them, i.e., the hierarchy
+// of graphs is flattened.
+func (gml *GraphML) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, gr := range gml.allGraphs() {
+		if err := gr.writeDOT(bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (gr *Graph) writeDOT(w *bufio.Writer) error {
+	directed := gr.edgesDirection != EdgeDirectionUndirected
+	for _, e := range gr.Edges {
+		directed = directed || e.directed()
+	}
+	graphType, edgeOp := "graph", "--"
+	if directed {
+		graphType, edgeOp = "digraph", "->"
+	}
+	_, _ = fmt.Fprintf(w, "%s %s {\n", graphType, dotString(gr.ID))
+
+	attrs, err := gr.GetAttributes()
+	if err != nil {
+		return err
+	}
+	if gr.Description != "" {
+		_, _ = fmt.Fprintf(w, "  label=%s;\n", dotString(gr.Description))
+	}
+	for _, name := range sortedNames(attrs) {
+		if name != "label" {
+			_, _ = fmt.Fprintf(w, "  %s=%s;\n", dotString(name), dotString(fmt.Sprint(attrs[name])))
+		}
+	}
+
+	for _, n := range gr.Nodes {
+		attrs, err := n.GetAttributes()
+		if err != nil {
+			return err
+		}
+		label := n.Description
+		if label == "" {
+			label = n.ID
+		}
+		_, _ = fmt.Fprintf(w, "  %s [%s];\n", dotString(n.ID), dotAttributes(label, attrs))
+	}
+	for _, e := range gr.Edges {
+		if gr.GetNode(e.Source) == nil {
+			return errors.New(fmt.Sprintf("source node: %s of edge: %s not found", e.Source, e.ID))
+		}
+		if gr.GetNode(e.Target) == nil {
+			return errors.New(fmt.Sprintf("target node: %s of edge: %s not found", e.Target, e.ID))
+		}
+		attrs, err := e.GetAttributes()
+		if err != nil {
+			return err
+		}
+		if directed && !e.directed() {
+			attrs["dir"] = "none"
+		}
+		list := dotAttributes(e.Description, attrs)
+		if list != "" {
+			list = " [" + list + "]"
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s %s%s;\n", dotString(e.Source), edgeOp, dotString(e.Target), list)
+	}
+	_, err = w.WriteString("}\n")
+	return err
+}
+
+// dotAttributes formats provided label and attributes as the items of DOT attributes list. The attribute named "label"
+// is skipped since labels are derived from descriptions.
+func dotAttributes(label string, attrs map[string]interface{}) string {
+	items := make([]string, 0, len(attrs)+1)
+	if label != "" {
+		items = append(items, "label="+dotString(label))
+	}
+	for _, name := range sortedNames(attrs) {
+		if name != "label" {
+			items = append(items, dotString(name)+"="+dotString(fmt.Sprint(attrs[name])))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// sortedNames returns the names of provided attributes in sorted order
+func sortedNames(attrs map[string]interface{}) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dotString quotes provided string escaping quotes, backslashes, and line breaks
+func dotString(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	value = strings.ReplaceAll(value, "\r\n", "\\n")
+	value = strings.ReplaceAll(value, "\n", "\\n")
+	value = strings.ReplaceAll(value, "\r", "\\n")
+	return "\"" + value + "\""
+}
@@ -0,0 +1,97 @@
+package graphml
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGraphML_WriteDOT(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test \"graph\"", EdgeDirectionDirected, map[string]interface{}{"version": 2})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"name": "first", "x": 1.5}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"name": "second \\ last"}, "multi\nline")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"weight": 0.5}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	n3, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n2, n3, nil, EdgeDirectionUndirected, "back")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.WriteDOT(outBuf)
+	require.NoError(t, err)
+
+	expected := `digraph "g0" {
+  label="test \"graph\"";
+  "version"="2";
+  "n0" [label="n0", "name"="first", "x"="1.5"];
+  "n1" [label="multi\nline", "name"="second \\ last"];
+  "n2" [label="n2", "name"=""];
+  "n0" -> "n1" ["weight"="0.5"];
+  "n1" -> "n2" [label="back", "dir"="none"];
+}
+`
+	assert.Equal(t, expected, outBuf.String())
+
+	// edge with missing node
+	gr.Edges[0].Target = "n42"
+	err = gml.WriteDOT(&bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestGraphML_WriteDOT_undirected(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.WriteDOT(outBuf)
+	require.NoError(t, err)
+
+	expected := `graph "g0" {
+  "n0" [label="n0"];
+  "n1" [label="n1"];
+  "n0" -- "n1";
+}
+digraph "g1" {
+}
+`
+	assert.Equal(t, expected, outBuf.String())
+}
+
+func TestGraphML_WriteDOT_nested(t *testing.T) {
+	gml := NewGraphML("")
+	err := gml.DecodeString(`<graphml><graph id="g0" edgedefault="directed">
+<node id="n0"><graph id="n0:g0" edgedefault="undirected"><node id="n0"/><node id="n1"/><edge source="n0" target="n1"/></graph></node>
+<node id="n1"/></graph></graphml>`)
+	require.NoError(t, err)
+
+	// the nested graphs are flattened into separate blocks
+	outBuf := &bytes.Buffer{}
+	err = gml.WriteDOT(outBuf)
+	require.NoError(t, err)
+	expected := `digraph "g0" {
+  "n0" [label="n0"];
+  "n1" [label="n1"];
+}
+graph "n0:g0" {
+  "n0" [label="n0"];
+  "n1" [label="n1"];
+  "n0" -- "n1";
+}
+`
+	assert.Equal(t, expected, outBuf.String())
+}
@@ -0,0 +1,505 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EncodeOptions The options to control encoding of the GraphML document
+type EncodeOptions struct {
+	// If set then each element begins on a new indented line. The indentation is inserted only between elements, thus
+	// the character data of values and descriptions is never altered. The whitespace characters significant for the
+	// values, e.g., newlines, are written as character references.
+	WithIndent bool
+	// The prefix and the indentation string of lines. If any of them set then each element begins on a new line
+	// indented with them instead of the default indentation applied by WithIndent.
+	IndentPrefix string
+	Indent       string
+	// If set then data elements of the graph are emitted before its nodes and edges, otherwise after them
+	GraphDataFirst bool
+	// The schema location to emit instead of the one held by GraphML, e.g., to refer the local copy of the schema
+	SchemaLocation string
+	// If set then the xmlns:xsi and xsi:schemaLocation attributes are omitted. They are omitted as well if any key
+	// has the format of values (see Key.Format) and SchemaLocation is not set, since the ggml:format attribute of
+	// such keys is not declared by GraphML schema, thus the document would fail validation against it.
+	OmitSchemaLocation bool
+	// If set then the location of GraphML 1.1 schema is emitted (see SchemaLocation11), unless SchemaLocation set
+	Version11 bool
+	// If set then the parse hints computed from the contents of each graph are emitted, otherwise only the hints
+	// set by Graph.ComputeParseHints are emitted (see ParseHints)
+	ParseHints bool
+	// If set then data elements with value equal to the default value of their keys are omitted. Such values are
+	// restored from the key defaults when attributes are read after decoding.
+	OmitDefaultValues bool
+	// If set then data elements with empty value are omitted unless their keys have non-empty default value, i.e.,
+	// only the data elements which carry no information are omitted.
+	OmitEmptyData bool
+	// If set then descriptions containing XML special characters are wrapped into CDATA section instead of escaping
+	CDATADescriptions bool
+	// The charset of the output document (see CharsetUTF8, CharsetUTF16, etc.). If set then the XML declaration with
+	// this charset is emitted. Otherwise, the document is written in UTF-8 without XML declaration.
+	Charset string
+}
+
+// EncodeWithOptions encodes GraphML into provided Writer according to the given options. The output is deterministic:
+// the keys are emitted in the order of their registration and the data elements of each element are emitted in the
+// order of registration of their keys, followed by the data elements referring undeclared keys in the original order.
+func (gml *GraphML) EncodeWithOptions(w io.Writer, options EncodeOptions) error {
+	if options.Charset != "" {
+		var err error
+		if w, err = newCharsetWriter(w, options.Charset); err != nil {
+			return err
+		}
+	}
+	enc := xml.NewEncoder(w)
+	if options.IndentPrefix != "" || options.Indent != "" {
+		enc.Indent(options.IndentPrefix, options.Indent)
+	} else if options.WithIndent {
+		enc.Indent("  ", "    ")
+	}
+	e := &encoder{enc: enc, options: options, gml: gml, keysOrder: make(map[string]int, len(gml.Keys))}
+	for i, k := range gml.Keys {
+		if _, ok := e.keysOrder[k.ID]; !ok {
+			e.keysOrder[k.ID] = i
+		}
+	}
+	var err error
+	if options.Charset != "" {
+		err = enc.EncodeToken(xml.ProcInst{
+			Target: "xml",
+			Inst:   []byte(fmt.Sprintf(`version="1.0" encoding="%s"`, strings.ToUpper(options.Charset))),
+		})
+	}
+	if err == nil {
+		err = e.encodeGraphML(gml)
+	}
+	if err == nil {
+		err = enc.Flush()
+	}
+	return err
+}
+
+// EncodeNodeSubset encodes into provided Writer the GraphML document which holds only the nodes of this graph with
+// given IDs and the edges connecting them. The document declares only the keys referenced by the data of encoded
+// elements or providing default values for them. The IDs not found in this graph are ignored.
+func (gr *Graph) EncodeNodeSubset(w io.Writer, nodeIDs []string, withIndent bool) error {
+	ids := make(map[string]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		ids[id] = true
+	}
+	gml, err := gr.subsetDocument(ids)
+	if err != nil {
+		return err
+	}
+	return gml.Encode(w, withIndent)
+}
+
+// EncodeComponents splits this graph into connected components (see ConnectedComponents) and writes each of them
+// as standalone GraphML document into the file within given directory. The files are named by the given prefix
+// followed by the index of component and ".graphml" extension. Each document declares only the keys referenced by
+// the data of its elements or providing default values for them. Returns the paths of written files in order of
+// components.
+func (gr *Graph) EncodeComponents(dir, prefix string, withIndent bool) ([]string, error) {
+	components := gr.ConnectedComponents()
+	paths := make([]string, len(components))
+	for i, component := range components {
+		ids := make(map[string]bool, len(component))
+		for _, n := range component {
+			ids[n.ID] = true
+		}
+		gml, err := gr.subsetDocument(ids)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%s%d.graphml", prefix, i))
+		if err = encodeToFile(gml, paths[i], withIndent); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// encodeToFile encodes provided GraphML into the file with given path
+func encodeToFile(gml *GraphML, path string, withIndent bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = gml.Encode(f, withIndent); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// subsetDocument creates new GraphML document holding the copy of this graph with only the nodes with given IDs and
+// the edges connecting them. The document declares only the keys referenced by the data of its elements or providing
+// default values for them.
+func (gr *Graph) subsetDocument(ids map[string]bool) (*GraphML, error) {
+	gml := gr.parent.cloneKeys()
+	gr.copyInto(gml, func(n *Node) bool { return ids[n.ID] }, nil)
+	if err := gml.pruneKeys(); err != nil {
+		return nil, err
+	}
+	return gml, nil
+}
+
+// pruneKeys removes all keys which are not referenced by any data of this GraphML, except the keys with default value
+// applicable to any of its elements
+func (gml *GraphML) pruneKeys() error {
+	used := make(map[string]bool)
+	gml.forEachData(func(d *Data) {
+		used[d.Key] = true
+	})
+	targets := gml.elementTargets()
+	unused := make([]*Key, 0)
+	for _, k := range gml.Keys {
+		if !used[k.ID] && !(k.DefaultValue != "" && k.appliesToAny(targets)) {
+			unused = append(unused, k)
+		}
+	}
+	for _, k := range unused {
+		if err := gml.RemoveKey(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elementTargets returns the set of key targets of the elements present in this GraphML
+func (gml *GraphML) elementTargets() map[KeyForElement]bool {
+	targets := map[KeyForElement]bool{KeyForGraphML: true}
+	for _, gr := range gml.allGraphs() {
+		targets[KeyForGraph] = true
+		for _, n := range gr.Nodes {
+			targets[KeyForNode] = true
+			if len(n.Ports) > 0 {
+				targets[KeyForPort] = true
+			}
+		}
+		if len(gr.Edges) > 0 {
+			targets[KeyForEdge] = true
+		}
+	}
+	return targets
+}
+
+// encoder The encoder of the GraphML elements applying encoding options
+type encoder struct {
+	enc     *xml.Encoder
+	options EncodeOptions
+	gml     *GraphML
+	// the position of keys in the order of registration
+	keysOrder map[string]int
+}
+
+func (e *encoder) encodeGraphML(gml *GraphML) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "graphml"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: gml.XmlNS},
+		},
+	}
+	formatted := gml.hasFormattedKeys()
+	if !e.options.OmitSchemaLocation && (!formatted || e.options.SchemaLocation != "") {
+		schemaLocation := gml.XsiSchemaLocation
+		if e.options.SchemaLocation != "" {
+			schemaLocation = e.options.SchemaLocation
+		} else if e.options.Version11 {
+			schemaLocation = SchemaLocation11
+		}
+		start.Attr = append(start.Attr,
+			xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: gml.XmlnsXsi},
+			xml.Attr{Name: xml.Name{Local: "xsi:schemaLocation"}, Value: schemaLocation})
+	}
+	names := make([]string, 0, len(gml.ExtraAttrs))
+	for name := range gml.ExtraAttrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: gml.ExtraAttrs[name]})
+	}
+	if _, ok := gml.ExtraAttrs["xmlns:xlink"]; !ok && gml.hasLocators() {
+		// the namespace of locator references
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:xlink"}, Value: XlinkNamespace})
+	}
+	if _, ok := gml.ExtraAttrs["xmlns:ggml"]; !ok && formatted {
+		// the namespace of the format of key values
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:ggml"}, Value: FormatNamespace})
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(gml.Description); err != nil {
+		return err
+	}
+	for _, k := range gml.Keys {
+		if err := e.encodeKey(k); err != nil {
+			return err
+		}
+	}
+	if err := e.encodeData(gml.Data); err != nil {
+		return err
+	}
+	for _, gr := range gml.Graphs {
+		if err := e.encodeGraph(gr); err != nil {
+			return err
+		}
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodeKey(k *Key) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "key"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "id"}, Value: k.ID},
+		},
+	}
+	if k.Target != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "for"}, Value: string(k.Target)})
+	}
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "attr.name"}, Value: k.Name},
+		xml.Attr{Name: xml.Name{Local: "attr.type"}, Value: string(k.KeyType)})
+	if k.Format != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "ggml:format"}, Value: k.Format})
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(k.Description); err != nil {
+		return err
+	}
+	if k.DefaultValue != "" {
+		if err := e.enc.EncodeElement(k.DefaultValue, xml.StartElement{Name: xml.Name{Local: "default"}}); err != nil {
+			return err
+		}
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodeGraph(gr *Graph) error {
+	// the optional attributes are omitted if not set to be kept absent after decoding
+	start := xml.StartElement{Name: xml.Name{Local: "graph"}}
+	if gr.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: gr.ID})
+	}
+	if gr.EdgeDefault != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "edgedefault"}, Value: gr.EdgeDefault})
+	}
+	if e.options.ParseHints {
+		start.Attr = append(start.Attr, gr.parseHints().attrs()...)
+	} else if gr.ParseHints != nil {
+		start.Attr = append(start.Attr, gr.ParseHints.attrs()...)
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(gr.Description); err != nil {
+		return err
+	}
+	if err := e.encodeLocator(gr.Locator); err != nil {
+		return err
+	}
+	if e.options.GraphDataFirst {
+		if err := e.encodeData(gr.Data); err != nil {
+			return err
+		}
+	}
+	for _, n := range gr.Nodes {
+		if err := e.encodeNode(n); err != nil {
+			return err
+		}
+	}
+	for _, edge := range gr.Edges {
+		if err := e.encodeEdge(edge); err != nil {
+			return err
+		}
+	}
+	if !e.options.GraphDataFirst {
+		if err := e.encodeData(gr.Data); err != nil {
+			return err
+		}
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodeNode(n *Node) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "node"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "id"}, Value: n.ID},
+		},
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(n.Description); err != nil {
+		return err
+	}
+	if err := e.encodeData(n.Data); err != nil {
+		return err
+	}
+	for _, p := range n.Ports {
+		if err := e.encodePort(p); err != nil {
+			return err
+		}
+	}
+	for _, gr := range n.Graphs {
+		if err := e.encodeGraph(gr); err != nil {
+			return err
+		}
+	}
+	if err := e.encodeLocator(n.Locator); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+// encodeLocator encodes locator element if provided locator is not nil
+func (e *encoder) encodeLocator(l *Locator) error {
+	if l == nil {
+		return nil
+	}
+	start := xml.StartElement{
+		Name: xml.Name{Local: "locator"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xlink:href"}, Value: l.Href},
+		},
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodePort(p *Port) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "port"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "name"}, Value: p.Name},
+		},
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(p.Description); err != nil {
+		return err
+	}
+	if err := e.encodeData(p.Data); err != nil {
+		return err
+	}
+	for _, nested := range p.Ports {
+		if err := e.encodePort(nested); err != nil {
+			return err
+		}
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodeEdge(edge *Edge) error {
+	start := xml.StartElement{Name: xml.Name{Local: "edge"}}
+	if edge.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: edge.ID})
+	}
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "source"}, Value: edge.Source},
+		xml.Attr{Name: xml.Name{Local: "target"}, Value: edge.Target})
+	if edge.Directed != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "directed"}, Value: edge.Directed})
+	}
+	if edge.SourcePortName != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "sourceport"}, Value: edge.SourcePortName})
+	}
+	if edge.TargetPortName != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "targetport"}, Value: edge.TargetPortName})
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.encodeDescription(edge.Description); err != nil {
+		return err
+	}
+	if err := e.encodeData(edge.Data); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *encoder) encodeDescription(description string) error {
+	if description == "" {
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: "desc"}}
+	// the carriage returns can not be kept in CDATA section as the line endings are normalized by XML parsers
+	if e.options.CDATADescriptions && strings.ContainsAny(description, "<>&") && !strings.Contains(description, "\r") {
+		cdata := struct {
+			Text string `xml:",cdata"`
+		}{Text: description}
+		return e.enc.EncodeElement(cdata, start)
+	}
+	return e.enc.EncodeElement(description, start)
+}
+
+func (e *encoder) encodeData(data []*Data) error {
+	for _, d := range e.sortedData(data) {
+		if e.options.OmitDefaultValues && e.isDefaultValue(d) {
+			continue
+		}
+		if e.options.OmitEmptyData && e.isEmptyValue(d) {
+			continue
+		}
+		if raw, ok := d.complexValue(); ok {
+			// keep nested XML of complex value as is
+			complex := struct {
+				ID       string `xml:"id,attr,omitempty"`
+				Key      string `xml:"key,attr"`
+				InnerXML string `xml:",innerxml"`
+			}{ID: d.ID, Key: d.Key, InnerXML: raw}
+			if err := e.enc.EncodeElement(complex, xml.StartElement{Name: xml.Name{Local: "data"}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.enc.EncodeElement(d, xml.StartElement{Name: xml.Name{Local: "data"}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedData returns copy of given data list sorted in the order of registration of the keys. The data referring
+// undeclared keys are kept at the end in the original order.
+func (e *encoder) sortedData(data []*Data) []*Data {
+	sorted := make([]*Data, len(data))
+	copy(sorted, data)
+	order := func(d *Data) int {
+		if i, ok := e.keysOrder[d.Key]; ok {
+			return i
+		}
+		return len(e.keysOrder)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return order(sorted[i]) < order(sorted[j])
+	})
+	return sorted
+}
+
+// isDefaultValue checks if given data has value equal to the default value of its key
+func (e *encoder) isDefaultValue(d *Data) bool {
+	key, ok := e.gml.keysById[d.Key]
+	return ok && key.DefaultValue != "" && d.Value == key.DefaultValue
+}
+
+// isEmptyValue checks if given data has empty value and its key has no default value to differ from
+func (e *encoder) isEmptyValue(d *Data) bool {
+	key, ok := e.gml.keysById[d.Key]
+	return ok && key.DefaultValue == "" && d.Value == ""
+}
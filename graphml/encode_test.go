@@ -0,0 +1,581 @@
+package graphml
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphML_EncodeWithOptions_GraphDataFirst(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, map[string]interface{}{"name": "graph"})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	const dataElement = "<data key=\"d0\">graph</data>"
+	const elements = "<node id=\"n0\"><desc>#1</desc></node><node id=\"n1\"><desc>#2</desc></node><edge id=\"e0\" source=\"n0\" target=\"n1\"></edge>"
+
+	// data last by default
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<desc>test graph</desc>"+elements+dataElement+"</graph>")
+
+	// data first
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{GraphDataFirst: true})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<desc>test graph</desc>"+dataElement+elements+"</graph>")
+
+	// check that both variants are decoded equally
+	decoded := NewGraphML("")
+	err = decoded.Decode(strings.NewReader(outBuf.String()))
+	require.NoError(t, err)
+	require.Len(t, decoded.Graphs, 1)
+	attrs, err := decoded.Graphs[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "graph"}, attrs)
+	assert.Len(t, decoded.Graphs[0].Nodes, 2)
+	assert.Len(t, decoded.Graphs[0].Edges, 1)
+}
+
+func TestGraphML_EncodeIndent(t *testing.T) {
+	gml := NewGraphML("test")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeIndent(outBuf, "", "  ")
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "\n  <desc>test</desc>\n  <graph id=\"g0\" edgedefault=\"directed\">\n    <node id=\"n0\"></node>\n  </graph>\n</graphml>")
+
+	// with prefix
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{IndentPrefix: "#", Indent: "\t"})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "\n#\t<graph id=\"g0\" edgedefault=\"directed\">\n#\t\t<node id=\"n0\"></node>\n#\t</graph>\n#</graphml>")
+}
+
+func TestGraphML_Encode_Deterministic(t *testing.T) {
+	gml := NewGraphML("test")
+	_, err := gml.RegisterKey(KeyForAll, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, map[string]interface{}{"name": "graph", "weight": 2.0})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"label": "n1", "color": "red", "weight": 3.0}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"color": "blue"}, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"weight": 4.0, "label": "e"}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	// the data appended after key registration
+	err = n2.SetAttribute("weight", 5.0)
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	encoded := outBuf.String()
+	assert.Contains(t, encoded, "<node id=\"n1\"><data key=\"d0\">5</data><data key=\"d2\">blue</data></node>")
+
+	for i := 0; i < 10; i++ {
+		outBuf.Reset()
+		err = gml.Encode(outBuf, false)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, outBuf.String())
+	}
+
+	// the decoded document is encoded identically
+	decoded := NewGraphML("")
+	err = decoded.Decode(strings.NewReader(encoded))
+	require.NoError(t, err)
+	outBuf.Reset()
+	err = decoded.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, outBuf.String())
+}
+
+func TestGraphML_Encode_SpecialCharacters(t *testing.T) {
+	const description = "A & B < C > \"D\" 'E'"
+	attributes := map[string]interface{}{
+		"lines":  "line 1\n  line 2\r\n\tline 3\n",
+		"padded": "  padded  ",
+		"markup": "<b>bold</b> &amp; ]]>",
+	}
+	gml := NewGraphML(description)
+	gr, err := gml.AddGraph(description, EdgeDirectionDirected, attributes)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(attributes, description)
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "multi\r\nline & more\n")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, attributes, EdgeDirectionDefault, description)
+	require.NoError(t, err)
+
+	for _, options := range []EncodeOptions{
+		{},
+		{WithIndent: true},
+		{Indent: "\t"},
+		{WithIndent: true, CDATADescriptions: true},
+	} {
+		outBuf := &bytes.Buffer{}
+		err = gml.EncodeWithOptions(outBuf, options)
+		require.NoError(t, err)
+
+		decoded := NewGraphML("")
+		err = decoded.Decode(outBuf)
+		require.NoError(t, err, "options: %+v", options)
+		require.Len(t, decoded.Graphs, 1)
+		dg := decoded.Graphs[0]
+		assert.Equal(t, description, decoded.Description, "options: %+v", options)
+		assert.Equal(t, description, dg.Description, "options: %+v", options)
+		assert.Equal(t, description, dg.Nodes[0].Description, "options: %+v", options)
+		assert.Equal(t, "multi\r\nline & more\n", dg.Nodes[1].Description, "options: %+v", options)
+		assert.Equal(t, description, dg.Edges[0].Description, "options: %+v", options)
+		for _, getAttributes := range []func() (map[string]interface{}, error){
+			dg.GetAttributes, dg.Nodes[0].GetAttributes, dg.Edges[0].GetAttributes,
+		} {
+			attrs, err := getAttributes()
+			require.NoError(t, err)
+			assert.Equal(t, attributes, attrs, "options: %+v", options)
+		}
+	}
+}
+
+func TestGraphML_Encode_IndentKeepsValues(t *testing.T) {
+	values := []string{"", " ", "\n", "\n  indented\n", "\t\ttabs", "trailing \n  "}
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "text", "", reflect.String, "\n default \n")
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, value := range values {
+		_, err = gr.AddNode(map[string]interface{}{"text": value}, value)
+		require.NoError(t, err)
+	}
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, true)
+	require.NoError(t, err)
+	// the element holding character data is not indented inside
+	assert.Contains(t, outBuf.String(), "<desc>&#xA;  indented&#xA;</desc>\n")
+	assert.Contains(t, outBuf.String(), "<data key=\"d0\">&#xA;  indented&#xA;</data>\n")
+
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	assert.Equal(t, "\n default \n", decoded.Keys[0].DefaultValue)
+	require.Len(t, decoded.Graphs[0].Nodes, len(values))
+	for i, n := range decoded.Graphs[0].Nodes {
+		value, present := n.RawAttribute("text")
+		assert.True(t, present)
+		assert.Equal(t, values[i], value)
+		if values[i] != "" {
+			assert.Equal(t, values[i], n.Description)
+		}
+	}
+}
+
+func TestGraphML_EncodeWithOptions_SchemaLocation(t *testing.T) {
+	gml := NewGraphML("test")
+
+	outBuf := &bytes.Buffer{}
+	err := gml.EncodeWithOptions(outBuf, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\"><desc>test</desc></graphml>", outBuf.String())
+
+	// override
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{SchemaLocation: "http://graphml.graphdrawing.org/xmlns file:///schemas/graphml.xsd"})
+	require.NoError(t, err)
+	assert.Equal(t, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns file:///schemas/graphml.xsd\"><desc>test</desc></graphml>", outBuf.String())
+
+	// omit
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{OmitSchemaLocation: true})
+	require.NoError(t, err)
+	assert.Equal(t, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\"><desc>test</desc></graphml>", outBuf.String())
+
+	// the GraphML itself is intact
+	assert.Equal(t, "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd", gml.XsiSchemaLocation)
+}
+
+func TestGraphML_EncodeWithOptions_Version11(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("n0", "n1", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gml.AddGraph("", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Version11: true, ParseHints: true})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `xsi:schemaLocation="`+SchemaLocation11+`"`)
+	assert.Contains(t, outBuf.String(), `<graph id="g0" edgedefault="directed" parse.nodes="3" parse.edges="1" parse.order="nodesfirst" parse.nodeids="canonical">`)
+	assert.Contains(t, outBuf.String(), `<graph id="g1" edgedefault="undirected" parse.nodes="0" parse.edges="0" parse.order="nodesfirst" parse.nodeids="canonical">`)
+	withHints := outBuf.String()
+
+	// the explicit schema location wins
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Version11: true, SchemaLocation: "local.xsd"})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `xsi:schemaLocation="local.xsd"`)
+	assert.NotContains(t, outBuf.String(), "parse.")
+
+	// the hints are ignored when decoded
+	decoded := NewGraphML("")
+	err = decoded.DecodeString(withHints)
+	require.NoError(t, err)
+	assert.Equal(t, 3, decoded.Graphs[0].NodeCount())
+}
+
+func TestGraph_ComputeParseHints(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("n0", "n1", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+
+	// not emitted by default
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.NotContains(t, outBuf.String(), "parse.")
+
+	gr.ComputeParseHints()
+	assert.Equal(t, &ParseHints{Nodes: 2, Edges: 1, Order: ParseOrderNodesFirst, NodeIDs: ParseNodeIDsCanonical}, gr.ParseHints)
+	outBuf.Reset()
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `<graph id="g0" edgedefault="directed" parse.nodes="2" parse.edges="1" parse.order="nodesfirst" parse.nodeids="canonical">`)
+
+	// the IDs not following the canonical scheme
+	_, err = gr.AddEdgeByID("n1", "custom", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	gr.ComputeParseHints()
+	assert.Equal(t, &ParseHints{Nodes: 3, Edges: 2, Order: ParseOrderNodesFirst, NodeIDs: ParseNodeIDsFree}, gr.ParseHints)
+}
+
+func TestGraphML_EncodeWithOptions_OmitDefaultValues(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "color", "", reflect.String, "black")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	attributes := []map[string]interface{}{
+		{"weight": 1.0, "color": "black", "label": ""},
+		{"weight": 2.5, "color": "black", "label": "second"},
+		{"weight": 1.0, "color": "white", "label": "third"},
+	}
+	for _, attrs := range attributes {
+		_, err = gr.AddNode(attrs, "")
+		require.NoError(t, err)
+	}
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{OmitDefaultValues: true})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<node id=\"n0\"><data key=\"d2\"></data></node>")
+	assert.Contains(t, outBuf.String(), "<node id=\"n1\"><data key=\"d0\">2.5</data><data key=\"d2\">second</data></node>")
+	assert.Contains(t, outBuf.String(), "<node id=\"n2\"><data key=\"d1\">white</data><data key=\"d2\">third</data></node>")
+
+	// check that omitted values restored from defaults
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.Len(t, decoded.Graphs, 1)
+	require.Len(t, decoded.Graphs[0].Nodes, len(attributes))
+	for i, n := range decoded.Graphs[0].Nodes {
+		attrs, err := n.GetAttributes()
+		require.NoError(t, err)
+		assert.Equal(t, attributes[i], attrs, "wrong attributes of node: %s", n.ID)
+	}
+
+	// the data kept by default
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<node id=\"n0\"><data key=\"d0\">1</data><data key=\"d1\">black</data><data key=\"d2\"></data></node>")
+}
+
+func TestGraphML_EncodeWithOptions_OmitEmptyData(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForEdge, "color", "", reflect.String, "black")
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"label": ""}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"label": "second"}, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"color": ""}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{OmitEmptyData: true})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<node id=\"n0\"></node>")
+	assert.Contains(t, outBuf.String(), "<node id=\"n1\"><data key=\"d0\">second</data></node>")
+	// the empty value differing from the default is kept
+	assert.Contains(t, outBuf.String(), "<edge id=\"e0\" source=\"n0\" target=\"n1\"><data key=\"d1\"></data></edge>")
+
+	// check that attributes are restored after decoding
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.Len(t, decoded.Graphs, 1)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"label": ""}, attrs)
+	attrs, err = decoded.Graphs[0].Edges[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": ""}, attrs)
+
+	// the empty data kept by default
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<node id=\"n0\"><data key=\"d0\"></data></node>")
+}
+
+func TestGraphML_EncodeWithOptions_CDATADescriptions(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_cdata_description.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+
+	// check that CDATA markers are not part of decoded descriptions
+	assert.Equal(t, "Dependencies of <core> & <util> modules", gml.Description)
+	assert.Equal(t, "The module name, e.g. <core>", gml.Keys[0].Description)
+	graph := gml.Graphs[0]
+	assert.Equal(t, "a -> b && b -> c", graph.Description)
+	assert.Equal(t, "<core>", graph.Nodes[0].Description)
+	assert.Equal(t, "plain description", graph.Nodes[1].Description)
+	assert.Equal(t, "uses \"util\" & friends", graph.Edges[0].Description)
+
+	// encode with CDATA
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{CDATADescriptions: true})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<desc><![CDATA[Dependencies of <core> & <util> modules]]></desc>")
+	assert.Contains(t, outBuf.String(), "<desc><![CDATA[The module name, e.g. <core>]]></desc>")
+	assert.Contains(t, outBuf.String(), "<desc><![CDATA[<core>]]></desc>")
+	assert.Contains(t, outBuf.String(), "<desc>plain description</desc>")
+
+	// round trip
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	assert.Equal(t, gml.Description, decoded.Description)
+	assert.Equal(t, gml.Keys[0].Description, decoded.Keys[0].Description)
+	assert.Equal(t, graph.Description, decoded.Graphs[0].Description)
+	assert.Equal(t, graph.Nodes[0].Description, decoded.Graphs[0].Nodes[0].Description)
+	assert.Equal(t, graph.Edges[0].Description, decoded.Graphs[0].Edges[0].Description)
+
+	// escaping by default
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<desc>&lt;core&gt;</desc>")
+}
+
+func TestGraph_EncodeNodeSubset(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n0, err := gr.AddNode(map[string]interface{}{"name": "n0"}, "")
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"size": 2}, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n0, n1, map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"label": "outer"}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gr.EncodeNodeSubset(outBuf, []string{"n0", "n1", "unknown"}, false)
+	require.NoError(t, err)
+	expected := "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\">" +
+		"<key id=\"d0\" for=\"node\" attr.name=\"name\" attr.type=\"string\"></key>" +
+		"<key id=\"d2\" for=\"edge\" attr.name=\"weight\" attr.type=\"double\"></key>" +
+		"<graph id=\"g0\" edgedefault=\"directed\"><desc>test graph</desc>" +
+		"<node id=\"n0\"><data key=\"d0\">n0</data></node><node id=\"n1\"></node>" +
+		"<edge id=\"e0\" source=\"n0\" target=\"n1\"><data key=\"d2\">1</data></edge></graph></graphml>"
+	assert.Equal(t, expected, outBuf.String())
+
+	// the source document is intact
+	assert.Len(t, gml.Keys, 4)
+	assert.Len(t, gr.Nodes, 3)
+}
+
+func TestGraph_EncodeNodeSubset_DefaultKeys(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "color", "", reflect.String, "red")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForEdge, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForPort, "side", "", reflect.String, "left")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n0, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n0, n1, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gr.EncodeNodeSubset(outBuf, []string{"n0"}, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.Len(t, decoded.Keys, 1)
+	assert.Equal(t, "color", decoded.Keys[0].Name)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, attrs)
+
+	// the key of edges is kept when edges exported
+	outBuf.Reset()
+	err = gr.EncodeNodeSubset(outBuf, []string{"n0", "n1"}, false)
+	require.NoError(t, err)
+	decoded = NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.Len(t, decoded.Keys, 2)
+	attrs, err = decoded.Graphs[0].Edges[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.0}, attrs)
+}
+
+func TestGraphML_Encode_FormattedKeys(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterJSONKey(KeyForNode, "tags", "", nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"tags": []string{"a"}}, "")
+	require.NoError(t, err)
+
+	str, err := gml.EncodeToString(false)
+	require.NoError(t, err)
+	assert.Contains(t, str, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:ggml="http://github.com/yaricom/goGraphML/xmlns">`)
+	assert.Contains(t, str, `attr.name="tags" attr.type="string" ggml:format="json"`)
+	assert.NotContains(t, str, "xsi:schemaLocation")
+
+	// the schema location set explicitly is kept
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{SchemaLocation: "graphml.xsd"})
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `xsi:schemaLocation="graphml.xsd"`)
+
+	// the namespace is declared once after round-trip
+	decoded := NewGraphML("")
+	err = decoded.DecodeString(str)
+	require.NoError(t, err)
+	assert.Equal(t, KeyFormatJSON, decoded.Keys[0].Format)
+	str, err = decoded.EncodeToString(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(str, "xmlns:ggml="))
+}
+
+func TestGraphML_EncodeWithOptions_Charset(t *testing.T) {
+	gml := NewGraphML("Граф 😀")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"name": "вершина"}, "")
+	require.NoError(t, err)
+
+	for _, charset := range []string{CharsetUTF8, CharsetUTF16, CharsetUTF16BE, "utf-16le"} {
+		outBuf := &bytes.Buffer{}
+		err = gml.EncodeWithOptions(outBuf, EncodeOptions{Charset: charset, WithIndent: true})
+		require.NoError(t, err, charset)
+
+		decoded := NewGraphML("")
+		err = decoded.Decode(bytes.NewReader(outBuf.Bytes()))
+		require.NoError(t, err, charset)
+		assert.Equal(t, gml.Description, decoded.Description, charset)
+		attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+		require.NoError(t, err, charset)
+		assert.Equal(t, "вершина", attrs["name"], charset)
+	}
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Charset: CharsetUTF16})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xFE, 0xFF, 0x00, '<', 0x00, '?', 0x00, 'x'}, outBuf.Bytes()[:8])
+
+	outBuf.Reset()
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Charset: CharsetUTF8})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(outBuf.String(), "<?xml version=\"1.0\" encoding=\"UTF-8\"?><graphml"))
+
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Charset: "KOI8-R"})
+	assert.EqualError(t, err, "unsupported charset: KOI8-R")
+}
+
+func TestGraph_EncodeComponents(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("components", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("a", "b", map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("c", "d", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	paths, err := gr.EncodeComponents(dir, "part_", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "part_0.graphml"), filepath.Join(dir, "part_1.graphml")}, paths)
+
+	expectedNodes := [][]string{{"a", "b"}, {"c", "d"}}
+	expectedKeys := []int{1, 0}
+	for i, path := range paths {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		decoded := NewGraphML("")
+		err = decoded.Decode(f)
+		_ = f.Close()
+		require.NoError(t, err)
+		require.Len(t, decoded.Graphs, 1)
+		component := decoded.Graphs[0]
+		require.Len(t, component.Nodes, 2)
+		assert.Equal(t, expectedNodes[i][0], component.Nodes[0].ID)
+		assert.Equal(t, expectedNodes[i][1], component.Nodes[1].ID)
+		assert.Len(t, component.Edges, 1)
+		assert.Len(t, decoded.Keys, expectedKeys[i])
+	}
+
+	_, err = gr.EncodeComponents(filepath.Join(dir, "missing"), "part_", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package graphml
+
+import "errors"
+
+// The errors returned by GraphML operations. The errors with details are wrapped around these values, thus they can be
+// checked by errors.Is.
+var (
+	// ErrKeyAlreadyRegistered the key with the same name is already registered for the target element
+	ErrKeyAlreadyRegistered = errors.New("key with given name already registered")
+	// ErrKeyNotFound the key is not registered with GraphML
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrEmptyAttributeNoDefault the attribute has no value and its key has no default value to substitute
+	ErrEmptyAttributeNoDefault = errors.New("empty attribute without default value")
+	// ErrNoEdgeDirection the default edge direction of the graph is not specified
+	ErrNoEdgeDirection = errors.New("default edge direction must be provided")
+	// ErrGraphNotFound the graph with given ID is not found in the GraphML
+	ErrGraphNotFound = errors.New("graph not found")
+	// ErrNodeNotFound the node with given ID is not found in the graph
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrEdgeAlreadyAdded the edge connecting the same nodes is already added to the graph
+	ErrEdgeAlreadyAdded = errors.New("edge already added to the graph")
+)
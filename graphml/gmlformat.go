@@ -0,0 +1,168 @@
+package graphml
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// the GML keys reserved for the structure of elements
+var gmlReservedKeys = map[string]bool{
+	"id":          true,
+	"label":       true,
+	"source":      true,
+	"target":      true,
+	"directed":    true,
+	"description": true,
+	"node":        true,
+	"edge":        true,
+	"graph":       true,
+}
+
+// EncodeGML encodes graphs of this GraphML into the provided Writer using Graph Modelling Language (GML) format.
+// Each graph is written as separate top-level "graph" list. The nodes get sequential integer GML IDs, while their
+// original IDs are stored as "label". The descriptions of elements are stored as "description" and the data attributes
+// are written as fields of the element. The attribute names are sanitized to be valid GML keys, and names colliding
+// with the structural keys of GML are prefixed with "attr". The names of element which become the same key after
+// sanitizing are disambiguated with numeric suffix in order of names, e.g., "nodecolor" and "nodecolor2" for
+// "node-color" and "nodecolor" respectively. The graphs nested into nodes are written as separate
+// top-level "graph" lists following the graph holding them, i.e., the hierarchy of graphs is flattened.
+func (gml *GraphML) EncodeGML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, gr := range gml.allGraphs() {
+		if err := gr.encodeGML(bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (gr *Graph) encodeGML(w *bufio.Writer) error {
+	directed := 1
+	if gr.edgesDirection == EdgeDirectionUndirected {
+		directed = 0
+	}
+	_, _ = fmt.Fprintf(w, "graph [\n  directed %d\n", directed)
+	_, _ = fmt.Fprintf(w, "  label %s\n", gmlString(gr.ID))
+	if err := writeGMLElement(w, "  ", gr.Description, gr.GetAttributes); err != nil {
+		return err
+	}
+
+	ids := make(map[string]int, len(gr.Nodes))
+	for i, n := range gr.Nodes {
+		ids[n.ID] = i
+		_, _ = fmt.Fprintf(w, "  node [\n    id %d\n    label %s\n", i, gmlString(n.ID))
+		if err := writeGMLElement(w, "    ", n.Description, n.GetAttributes); err != nil {
+			return err
+		}
+		_, _ = w.WriteString("  ]\n")
+	}
+	for _, e := range gr.Edges {
+		source, ok := ids[e.Source]
+		if !ok {
+			return errors.New(fmt.Sprintf("source node: %s of edge: %s not found", e.Source, e.ID))
+		}
+		target, ok := ids[e.Target]
+		if !ok {
+			return errors.New(fmt.Sprintf("target node: %s of edge: %s not found", e.Target, e.ID))
+		}
+		_, _ = fmt.Fprintf(w, "  edge [\n    source %d\n    target %d\n    label %s\n", source, target, gmlString(e.ID))
+		if err := writeGMLElement(w, "    ", e.Description, e.GetAttributes); err != nil {
+			return err
+		}
+		_, _ = w.WriteString("  ]\n")
+	}
+	_, err := w.WriteString("]\n")
+	return err
+}
+
+// writeGMLElement writes description and attributes of the element with given indentation
+func writeGMLElement(w *bufio.Writer, indent, description string, attributes func() (map[string]interface{}, error)) error {
+	if description != "" {
+		_, _ = fmt.Fprintf(w, "%sdescription %s\n", indent, gmlString(description))
+	}
+	attrs, err := attributes()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keys := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := gmlKey(name)
+		if keys[key] {
+			suffix := 2
+			for keys[key+strconv.Itoa(suffix)] {
+				suffix++
+			}
+			key += strconv.Itoa(suffix)
+		}
+		keys[key] = true
+		_, err = fmt.Fprintf(w, "%s%s %s\n", indent, key, gmlValue(attrs[name]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gmlKey converts attribute name into valid GML key, i.e., alphanumeric string starting with a letter
+func gmlKey(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9' && sb.Len() > 0) {
+			sb.WriteRune(r)
+		}
+	}
+	key := sb.String()
+	if key == "" || gmlReservedKeys[key] {
+		key = "attr" + key
+	}
+	return key
+}
+
+// gmlValue converts attribute value into GML value
+func gmlValue(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return gmlReal(float64(v), 32)
+	case float64:
+		return gmlReal(v, 64)
+	case string:
+		return gmlString(v)
+	default:
+		if _, err := floatValue(v); err == nil {
+			// integer types
+			return fmt.Sprint(v)
+		}
+		return gmlString(fmt.Sprint(v))
+	}
+}
+
+// gmlReal formats float value as GML real which must contain the decimal point
+func gmlReal(value float64, bitSize int) string {
+	res := strconv.FormatFloat(value, 'g', -1, bitSize)
+	if !strings.ContainsAny(res, ".eEIN") {
+		res += ".0"
+	}
+	return res
+}
+
+// gmlString quotes provided string escaping characters not allowed in GML strings
+func gmlString(value string) string {
+	value = strings.ReplaceAll(value, "&", "&amp;")
+	value = strings.ReplaceAll(value, "\"", "&quot;")
+	return "\"" + value + "\""
+}
@@ -0,0 +1,121 @@
+package graphml
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGraphML_EncodeGML(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test \"graph\"", EdgeDirectionDirected, map[string]interface{}{"version": 2})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"name": "first", "x": 1.0, "active": true}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"label": "second & last", "id": 42}, "#2")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"weight": float32(0.5), "edge-kind": "link"}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeGML(outBuf)
+	require.NoError(t, err)
+
+	expected := `graph [
+  directed 1
+  label "g0"
+  description "test &quot;graph&quot;"
+  version 2
+  node [
+    id 0
+    label "n0"
+    active 1
+    attrlabel ""
+    name "first"
+    x 1.0
+  ]
+  node [
+    id 1
+    label "n1"
+    description "#2"
+    attrid 42
+    attrlabel "second &amp; last"
+    name ""
+  ]
+  edge [
+    source 0
+    target 1
+    label "e0"
+    edgekind "link"
+    weight 0.5
+  ]
+]
+`
+	assert.Equal(t, expected, outBuf.String())
+
+	// edge with missing node
+	gr.Edges[0].Target = "n42"
+	err = gml.EncodeGML(&bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestGraphML_EncodeGML_KeyCollisions(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"node-color": "red", "nodecolor": "blue", "id": 1, "attrid": 2, "#": 3, "$": 4}, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeGML(outBuf)
+	require.NoError(t, err)
+
+	expected := `graph [
+  directed 1
+  label "g0"
+  node [
+    id 0
+    label "n0"
+    attr 3
+    attr2 4
+    attrid 2
+    attrid2 1
+    nodecolor "red"
+    nodecolor2 "blue"
+  ]
+]
+`
+	assert.Equal(t, expected, outBuf.String())
+}
+
+func TestGraphML_EncodeGML_nested(t *testing.T) {
+	gml := NewGraphML("")
+	err := gml.DecodeString(`<graphml><graph id="g0" edgedefault="directed">
+<node id="n0"><graph id="n0:g0" edgedefault="undirected"><node id="n0"/></graph></node>
+</graph></graphml>`)
+	require.NoError(t, err)
+
+	// the nested graphs are flattened into separate top-level lists
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeGML(outBuf)
+	require.NoError(t, err)
+	expected := `graph [
+  directed 1
+  label "g0"
+  node [
+    id 0
+    label "n0"
+  ]
+]
+graph [
+  directed 0
+  label "n0:g0"
+  node [
+    id 0
+    label "n0"
+  ]
+]
+`
+	assert.Equal(t, expected, outBuf.String())
+}
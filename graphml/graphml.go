@@ -2,13 +2,20 @@
 package graphml
 
 import (
+	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 // NotAValue The Not value of data attribute to substitute with default one if present
@@ -26,6 +33,8 @@ const (
 	KeyForNode KeyForElement = "node"
 	// KeyForEdge the data-function is for Edge element only
 	KeyForEdge KeyForElement = "edge"
+	// KeyForPort the data-function is for Port element only
+	KeyForPort KeyForElement = "port"
 	// KeyForAll the data-function is for all elements
 	KeyForAll KeyForElement = "all"
 )
@@ -38,7 +47,8 @@ const (
 	BooleanType DataType = "boolean"
 	// IntType single integer precision (reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16)
 	IntType DataType = "int"
-	// LongType double integer precision (reflect.Int64, reflect.Uint32)
+	// LongType double integer precision (reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64, reflect.Uintptr).
+	// The values are parsed as int64 unless the key has KeyFormatUint64 format.
 	LongType DataType = "long"
 	// FloatType single float precision (reflect.Float32)
 	FloatType DataType = "float"
@@ -48,6 +58,23 @@ const (
 	StringType DataType = "string"
 )
 
+// The formats of string keys holding values of types not supported by GraphML
+const (
+	// KeyFormatTime The format of keys holding time.Time values as RFC3339 strings (see RegisterTimeKey)
+	KeyFormatTime = "time"
+	// KeyFormatBigInt The format of keys holding *big.Int values as decimal strings (see RegisterBigIntKey)
+	KeyFormatBigInt = "bigint"
+	// KeyFormatBigFloat The format of keys holding *big.Float values as decimal strings (see RegisterBigFloatKey)
+	KeyFormatBigFloat = "bigfloat"
+	// KeyFormatJSON The format of keys holding composite values, e.g., maps and slices, as JSON strings
+	// (see RegisterJSONKey)
+	KeyFormatJSON = "json"
+)
+
+// KeyFormatUint64 The format of long keys holding unsigned 64-bit integer values, which are parsed as uint64
+// (see RegisterUint64Key)
+const KeyFormatUint64 = "uint64"
+
 // EdgeDirection The edge direction
 type EdgeDirection int
 
@@ -65,7 +92,47 @@ const (
 	edgeDirectionUndirected = "undirected"
 )
 
-// GraphML The root element
+// The default namespace declarations of GraphML document
+const (
+	// DefaultNamespace the GraphML namespace
+	DefaultNamespace = "http://graphml.graphdrawing.org/xmlns"
+	// DefaultXsiNamespace the XML schema instance namespace
+	DefaultXsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+	// XlinkNamespace the XLink namespace of locator references (see Locator)
+	XlinkNamespace = "http://www.w3.org/1999/xlink"
+	// FormatNamespace the namespace of the format of key values (see Key.Format), declared with ggml prefix
+	FormatNamespace = "http://github.com/yaricom/goGraphML/xmlns"
+	// DefaultSchemaLocation the location of GraphML schema
+	DefaultSchemaLocation = "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd"
+	// SchemaLocation11 the location of GraphML 1.1 schema
+	SchemaLocation11 = "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.1/graphml.xsd"
+)
+
+// The orders of nodes and edges in the graph element (see ParseHints)
+const (
+	// ParseOrderNodesFirst all nodes precede all edges
+	ParseOrderNodesFirst = "nodesfirst"
+	// ParseOrderAdjacencyList the edges follow their source nodes
+	ParseOrderAdjacencyList = "adjacencylist"
+	// ParseOrderFree no particular order
+	ParseOrderFree = "free"
+)
+
+// The schemes of node IDs in the graph element (see ParseHints)
+const (
+	// ParseNodeIDsCanonical the IDs are in form nX, where X is the number of nodes preceding the node
+	ParseNodeIDsCanonical = "canonical"
+	// ParseNodeIDsFree the IDs are arbitrary
+	ParseNodeIDsFree = "free"
+)
+
+// The separator of elements IDs in hierarchical path of the node
+const pathSeparator = "/"
+
+// GraphML The root element. The GraphML is safe for concurrent use by multiple goroutines as long as only reading
+// methods (Get*, Walk*, Encode*, Validate, algorithms, etc.) are called, e.g., after Decode completes. The reading
+// methods never modify the document. The methods modifying the document, e.g., Add*, Set*, Remove*, Register*, Decode,
+// must not be called concurrently with any other methods.
 type GraphML struct {
 	// The name of root element
 	XMLName xml.Name `xml:"graphml"`
@@ -83,13 +150,39 @@ type GraphML struct {
 	Data []*Data `xml:"data,omitempty"`
 	// The graph objects encapsulated
 	Graphs []*Graph `xml:"graph,omitempty"`
+	// The attributes of root element not defined by GraphML, e.g., vendor tags or namespace declarations, by their
+	// qualified names. These attributes are kept when decoded and emitted when encoded.
+	ExtraAttrs map[string]string `xml:"-"`
+
+	// The flag to disable coercion of float values without fractional part to the int/long data types
+	StrictNumericTypes bool `xml:"-"`
+	// The flag to include into attributes of the node the attributes of its graph defined by keys for all elements,
+	// unless the node has explicit data for the same key
+	InheritGraphAttributes bool `xml:"-"`
+	// The flag to return the raw string of data value instead of error when value can not be parsed according to
+	// the type of its key. The complex values, i.e., data elements holding nested XML, are returned as raw XML.
+	LenientParsing bool `xml:"-"`
+	// The flag to omit from attributes the data referring to the keys not declared instead of returning error
+	SkipUnknownKeys bool `xml:"-"`
+	// The creation timestamp, tracked if enabled (see EnableTimestamps)
+	Created time.Time `xml:"-"`
+	// The modification timestamp, tracked if enabled (see EnableTimestamps)
+	Modified time.Time `xml:"-"`
 
 	// The map to look for keys by their standard identifiers (see keyIdentifier(name string, target KeyForElement))
 	keysByIdentifier map[string]*Key
 	// The map to look for keys by their IDs. Useful for fast reverse mapping of Data -> Key -> Attribute Name/Type
 	keysById map[string]*Key
+	// The map to look for graphs by their IDs
+	graphsById map[string]*Graph
 	// The default key type to use when no key type specified
 	keyTypeDefault DataType
+	// The custom serializers of values per key type (see SetSerializer)
+	serializers map[DataType]func(interface{}) (string, error)
+	// The custom deserializers of values per key type (see SetDeserializer)
+	deserializers map[DataType]func(string) (interface{}, error)
+	// The flag to indicate whether timestamps are tracked
+	trackTimestamps bool
 }
 
 // Key the data function declaration.
@@ -109,6 +202,12 @@ type Key struct {
 	Description string `xml:"desc,omitempty"`
 	// The default value
 	DefaultValue string `xml:"default,omitempty"`
+	// The format of values (non-standard extension written as ggml:format attribute of FormatNamespace), e.g.,
+	// KeyFormatTime for time.Time values
+	Format string `xml:"http://github.com/yaricom/goGraphML/xmlns format,attr,omitempty"`
+
+	// The list of elements this key is for, if multiple targets declared (see targetList)
+	targets []KeyForElement
 }
 
 // Data the data function definition.
@@ -124,6 +223,17 @@ type Data struct {
 
 	// The data value associated with this element
 	Value string `xml:",chardata"`
+
+	// The value attribute of the data element set by some non-conformant producers instead of the character data
+	valueAttr string
+	// The raw XML of the complex value, i.e., the content of data element holding nested XML elements, which is valid
+	// while Value is equal to complexFrom
+	complexXML  string
+	complexFrom string
+	// The typed value set through the API, which is valid while Value is equal to typedFrom and key has typedType
+	typed     interface{}
+	typedFrom string
+	typedType DataType
 }
 
 // Graph Describes one graph in this document. Occurrence: <graphml>, <node>, <edge>, <hyperedge>.
@@ -141,17 +251,59 @@ type Graph struct {
 	Edges []*Edge `xml:"edge,omitempty"`
 	// The data associated with this node
 	Data []*Data `xml:"data,omitempty"`
+	// The reference to the external content of this graph
+	Locator *Locator `xml:"locator,omitempty"`
+	// The flag to allow multiple edges between the same nodes, i.e., AddEdge doesn't reject the parallel edges
+	AllowMultiEdges bool `xml:"-"`
+	// The parse hints emitted as attributes of graph element if set (see ComputeParseHints)
+	ParseHints *ParseHints `xml:"-"`
 
 	// The parent GraphML
 	parent *GraphML
+	// The node holding this graph if it is nested, nil for the top level graph
+	parentNode *Node
 	// The map of nodes, indexed by their ID
 	nodesMap map[string]*Node
-	// The map of edges by connected nodes
+	// The map of edges by connected nodes, holding the first of parallel edges
 	edgesMap map[string]*Edge
+	// The map of edges, indexed by their ID
+	edgesById map[string]*Edge
+	// The lists of edges, indexed by IDs of their source nodes
+	outEdges map[string][]*Edge
+	// The lists of edges, indexed by IDs of their target nodes
+	inEdges map[string][]*Edge
 	// The default edge direction flag
 	edgesDirection EdgeDirection
 }
 
+// ParseHints The parse.* attributes of graph element defined by GraphML 1.1, which let parsers preallocate and stream
+// the graph
+type ParseHints struct {
+	// The number of nodes in the graph (parse.nodes)
+	Nodes int
+	// The number of edges in the graph (parse.edges)
+	Edges int
+	// The order of nodes and edges in the graph element (parse.order)
+	Order string
+	// The scheme of node IDs (parse.nodeids)
+	NodeIDs string
+}
+
+// attrs returns the XML attributes of graph element holding these hints
+func (ph *ParseHints) attrs() []xml.Attr {
+	res := []xml.Attr{
+		{Name: xml.Name{Local: "parse.nodes"}, Value: strconv.Itoa(ph.Nodes)},
+		{Name: xml.Name{Local: "parse.edges"}, Value: strconv.Itoa(ph.Edges)},
+	}
+	if ph.Order != "" {
+		res = append(res, xml.Attr{Name: xml.Name{Local: "parse.order"}, Value: ph.Order})
+	}
+	if ph.NodeIDs != "" {
+		res = append(res, xml.Attr{Name: xml.Name{Local: "parse.nodeids"}, Value: ph.NodeIDs})
+	}
+	return res
+}
+
 // Node Describes one node in the <graph> containing this <node>. Occurrence: <graph>.
 type Node struct {
 	// The ID of this node element (in form nX, where X denotes the number of occurrences of the node element before the current one)
@@ -160,11 +312,47 @@ type Node struct {
 	Description string `xml:"desc,omitempty"`
 	// The data associated with this node
 	Data []*Data `xml:"data,omitempty"`
+	// The ports of this node, i.e., the points where edges can be attached
+	Ports []*Port `xml:"port,omitempty"`
+	// The nested graphs of this node, i.e., the content of hierarchical node
+	Graphs []*Graph `xml:"graph,omitempty"`
+	// The reference to the external content of this node
+	Locator *Locator `xml:"locator,omitempty"`
 
 	// The reference to the parent graph for reverse mapping
 	graph *Graph
 }
 
+// Locator Refers the external content of the node or graph by URI. Occurrence: <node>, <graph>.
+type Locator struct {
+	// The URI of the external content (xlink:href)
+	Href string `xml:"-"`
+}
+
+// clone creates copy of this locator, nil if locator is nil
+func (l *Locator) clone() *Locator {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	return &c
+}
+
+// Port The point of the node where edges can be attached. The ports can be nested. Occurrence: <node>, <port>.
+type Port struct {
+	// The name of this port, unique within the node
+	Name string `xml:"name,attr"`
+	// Provides human readable description
+	Description string `xml:"desc,omitempty"`
+	// The data associated with this port
+	Data []*Data `xml:"data,omitempty"`
+	// The nested ports
+	Ports []*Port `xml:"port,omitempty"`
+
+	// The reference to the node of this port for reverse mapping
+	node *Node
+}
+
 // Edge Describes an edge in the <graph> which contains this <edge>. Occurrence: <graph>.
 type Edge struct {
 	// The ID of this edge element (in form eX, where X is the number of edge elements before this one)
@@ -175,6 +363,10 @@ type Edge struct {
 	Target string `xml:"target,attr"`
 	// The direction type of this edge (true - directed, false - undirected)
 	Directed string `xml:"directed,attr,omitempty"`
+	// The name of the port of the source node this edge is attached to, if any
+	SourcePortName string `xml:"sourceport,attr,omitempty"`
+	// The name of the port of the target node this edge is attached to, if any
+	TargetPortName string `xml:"targetport,attr,omitempty"`
 
 	// Provides human readable description
 	Description string `xml:"desc,omitempty"`
@@ -192,11 +384,12 @@ func NewGraphMLWithDefaultKeyType(description string, keyTypeDefault DataType) *
 		Keys:              make([]*Key, 0),
 		Data:              make([]*Data, 0),
 		Graphs:            make([]*Graph, 0),
-		XmlNS:             "http://graphml.graphdrawing.org/xmlns",
-		XmlnsXsi:          "http://www.w3.org/2001/XMLSchema-instance",
-		XsiSchemaLocation: "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd",
+		XmlNS:             DefaultNamespace,
+		XmlnsXsi:          DefaultXsiNamespace,
+		XsiSchemaLocation: DefaultSchemaLocation,
 		keysByIdentifier:  make(map[string]*Key),
 		keysById:          make(map[string]*Key),
+		graphsById:        make(map[string]*Graph),
 		keyTypeDefault:    keyTypeDefault,
 	}
 	return &gml
@@ -207,6 +400,22 @@ func NewGraphML(description string) *GraphML {
 	return NewGraphMLWithDefaultKeyType(description, StringType)
 }
 
+// SetNamespace sets the GraphML namespace, the XML schema instance namespace, and the schema location declared by
+// the encoded document. The empty value restores the default one (see DefaultNamespace, DefaultXsiNamespace, and
+// DefaultSchemaLocation).
+func (gml *GraphML) SetNamespace(xmlns, xsi, schemaLocation string) {
+	if xmlns == "" {
+		xmlns = DefaultNamespace
+	}
+	if xsi == "" {
+		xsi = DefaultXsiNamespace
+	}
+	if schemaLocation == "" {
+		schemaLocation = DefaultSchemaLocation
+	}
+	gml.XmlNS, gml.XmlnsXsi, gml.XsiSchemaLocation = xmlns, xsi, schemaLocation
+}
+
 // NewGraphMLWithAttributes creates new GraphML instance with given attributes
 func NewGraphMLWithAttributes(description string, attributes map[string]interface{}) (gml *GraphML, err error) {
 	gml = NewGraphML(description)
@@ -219,67 +428,89 @@ func NewGraphMLWithAttributes(description string, attributes map[string]interfac
 
 // Encode encodes GraphML into provided Writer. If withIndent set then each element begins on a new indented line.
 func (gml *GraphML) Encode(w io.Writer, withIndent bool) error {
-	enc := xml.NewEncoder(w)
-	if withIndent {
-		enc.Indent("  ", "    ")
-	}
-	err := enc.Encode(gml)
-	if err == nil {
-		err = enc.Flush()
+	return gml.EncodeWithOptions(w, EncodeOptions{WithIndent: withIndent})
+}
+
+// EncodeIndent encodes GraphML into provided Writer. Each element begins on a new line starting with the given prefix
+// followed by one or more copies of the given indent according to the nesting depth.
+func (gml *GraphML) EncodeIndent(w io.Writer, prefix, indent string) error {
+	return gml.EncodeWithOptions(w, EncodeOptions{IndentPrefix: prefix, Indent: indent})
+}
+
+// EncodeToString encodes GraphML into string. If withIndent set then each element begins on a new indented line.
+func (gml *GraphML) EncodeToString(withIndent bool) (string, error) {
+	var sb strings.Builder
+	if err := gml.Encode(&sb, withIndent); err != nil {
+		return "", err
 	}
-	return err
+	return sb.String(), nil
 }
 
 // Decode decodes GraphML from provided Reader
 func (gml *GraphML) Decode(r io.Reader) error {
-	dec := xml.NewDecoder(r)
-	err := dec.Decode(gml)
+	return gml.DecodeWithOptions(r, DecodeOptions{})
+}
+
+// DecodeString decodes GraphML from provided string
+func (gml *GraphML) DecodeString(s string) error {
+	return gml.Decode(strings.NewReader(s))
+}
+
+// DecodeBytes decodes GraphML from provided byte slice
+func (gml *GraphML) DecodeBytes(data []byte) error {
+	return gml.Decode(bytes.NewReader(data))
+}
+
+// RegisterKey registers data function with GraphML instance. The key for reflect.Uint, reflect.Uint64 and
+// reflect.Uintptr kinds has KeyFormatUint64 format (see RegisterUint64Key).
+func (gml *GraphML) RegisterKey(target KeyForElement, name, description string, keyType reflect.Kind, defaultValue interface{}) (key *Key, err error) {
+	return gml.RegisterKeyWithID(gml.nextKeyId(), target, name, description, keyType, defaultValue)
+}
+
+// GetOrRegisterKey returns the key with given name and target if it already exists, otherwise registers new one.
+// Returns error if existing key has type or default value different from the requested ones. The nil default value
+// matches any default value of existing key.
+func (gml *GraphML) GetOrRegisterKey(target KeyForElement, name, description string, keyType reflect.Kind, defaultValue interface{}) (*Key, error) {
+	key := gml.GetKey(name, target)
+	if key == nil {
+		return gml.RegisterKey(target, name, description, keyType, defaultValue)
+	}
+	dataType, err := typeNameForKind(keyType)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// populate auxiliary data structure
-	for _, key := range gml.Keys {
-		if key.KeyType == "" {
-			key.KeyType = gml.keyTypeDefault
-		}
-		if key.Target == "" {
-			key.Target = KeyForAll
-		}
-		gml.keysByIdentifier[keyIdentifier(key.Name, key.Target)] = key
-		gml.keysById[key.ID] = key
+	if key.KeyType != dataType {
+		return nil, errors.New(fmt.Sprintf("key: %s already registered with type: %s, requested type: %s", name, key.KeyType, dataType))
 	}
-
-	for _, gr := range gml.Graphs {
-		gr.parent = gml
-		if gr.EdgeDefault == edgeDirectionDirected {
-			gr.edgesDirection = EdgeDirectionDirected
-		} else if gr.EdgeDefault == edgeDirectionUndirected {
-			gr.edgesDirection = EdgeDirectionUndirected
+	if defaultValue != nil {
+		if !key.unsigned() {
+			if defaultValue, err = gml.coerceValue(defaultValue, key.KeyType); err != nil {
+				return nil, err
+			}
 		}
-		// populate edges map and link them to their graph
-		gr.edgesMap = make(map[string]*Edge)
-		for _, e := range gr.Edges {
-			gr.edgesMap[edgeIdentifier(e.Source, e.Target)] = e
-			e.graph = gr
+		requested, err := gml.keyStringValue(defaultValue, key)
+		if err != nil {
+			return nil, err
 		}
-		// populate nodes map and link them to their graph
-		gr.nodesMap = make(map[string]*Node)
-		for _, n := range gr.Nodes {
-			gr.nodesMap[n.ID] = n
-			n.graph = gr
+		if requested != key.DefaultValue {
+			return nil, errors.New(fmt.Sprintf("key: %s already registered with default value: %s, requested default value: %s", name, key.DefaultValue, requested))
 		}
 	}
-
-	return err
+	return key, nil
 }
 
-// RegisterKey registers data function with GraphML instance
-func (gml *GraphML) RegisterKey(target KeyForElement, name, description string, keyType reflect.Kind, defaultValue interface{}) (key *Key, err error) {
+// RegisterKeyWithID registers data function with given ID with GraphML instance. Returns error if key with this ID
+// already exists.
+func (gml *GraphML) RegisterKeyWithID(id string, target KeyForElement, name, description string, keyType reflect.Kind, defaultValue interface{}) (key *Key, err error) {
 	if key := gml.GetKey(name, target); key != nil {
-		return nil, errors.New(fmt.Sprintf("key with given name already registered: %s", name))
+		return nil, fmt.Errorf("%w: %s", ErrKeyAlreadyRegistered, name)
+	}
+	if id == "" {
+		return nil, errors.New("key ID must be provided")
+	}
+	if _, ok := gml.keysById[id]; ok {
+		return nil, errors.New(fmt.Sprintf("key with ID: %s already exists", id))
 	}
-	id := gml.nextKeyId()
 	key = &Key{
 		ID:          id,
 		Target:      target,
@@ -290,10 +521,18 @@ func (gml *GraphML) RegisterKey(target KeyForElement, name, description string,
 	if key.KeyType, err = typeNameForKind(keyType); err != nil {
 		return nil, err
 	}
+	if unsignedLongKind(keyType) {
+		key.Format = KeyFormatUint64
+	}
 
 	// store default value
 	if defaultValue != nil {
-		if key.DefaultValue, err = stringValueIfSupported(defaultValue, key.KeyType); err != nil {
+		if !key.unsigned() {
+			if defaultValue, err = gml.coerceValue(defaultValue, key.KeyType); err != nil {
+				return nil, err
+			}
+		}
+		if key.DefaultValue, err = gml.keyStringValue(defaultValue, key); err != nil {
 			return nil, err
 		}
 	}
@@ -304,6 +543,99 @@ func (gml *GraphML) RegisterKey(target KeyForElement, name, description string,
 	return key, nil
 }
 
+// RegisterKeyFromValue registers data function with GraphML instance which type is inferred from the given value.
+// The value is used as the default value of the key. The time.Time, *big.Int and *big.Float values are supported
+// in the same way as by RegisterTimeKey, RegisterBigIntKey and RegisterBigFloatKey respectively. The key for uint,
+// uint64 and uintptr values has KeyFormatUint64 format (see RegisterUint64Key).
+func (gml *GraphML) RegisterKeyFromValue(target KeyForElement, name, description string, value interface{}) (*Key, error) {
+	if value == nil {
+		return nil, errors.New("value must be provided to infer the type of key")
+	}
+	if format := valueFormat(value); format != "" {
+		return gml.registerFormattedKey(target, name, description, format, value)
+	}
+	return gml.RegisterKey(target, name, description, reflect.TypeOf(value).Kind(), value)
+}
+
+// RegisterTimeKey registers data function with GraphML instance which holds time.Time values. The values are stored
+// as RFC3339 strings and parsed back into time.Time when attributes are requested. The default value must be
+// either nil or time.Time.
+func (gml *GraphML) RegisterTimeKey(target KeyForElement, name, description string, defaultValue interface{}) (*Key, error) {
+	return gml.registerFormattedKey(target, name, description, KeyFormatTime, defaultValue)
+}
+
+// RegisterBigIntKey registers data function with GraphML instance which holds *big.Int values. The values are stored
+// as decimal strings and parsed back into *big.Int when attributes are requested. The default value must be
+// either nil or *big.Int.
+func (gml *GraphML) RegisterBigIntKey(target KeyForElement, name, description string, defaultValue interface{}) (*Key, error) {
+	return gml.registerFormattedKey(target, name, description, KeyFormatBigInt, defaultValue)
+}
+
+// RegisterBigFloatKey registers data function with GraphML instance which holds *big.Float values. The values are
+// stored as the shortest decimal strings representing them at their precision and parsed back into *big.Float with
+// the precision sufficient to hold all decimal digits when attributes are requested. The default value must be
+// either nil or *big.Float.
+func (gml *GraphML) RegisterBigFloatKey(target KeyForElement, name, description string, defaultValue interface{}) (*Key, error) {
+	return gml.registerFormattedKey(target, name, description, KeyFormatBigFloat, defaultValue)
+}
+
+// RegisterJSONKey registers data function with GraphML instance which holds composite values, e.g., maps, slices and
+// structs. The values are stored as JSON strings, thus they are seen as plain strings by consumers not aware of
+// KeyFormatJSON format. The values are unmarshalled back when attributes are requested into generic JSON values,
+// i.e., map[string]interface{}, []interface{}, float64, string, bool or nil. The default value, if not nil, must be
+// serializable into JSON.
+func (gml *GraphML) RegisterJSONKey(target KeyForElement, name, description string, defaultValue interface{}) (*Key, error) {
+	return gml.registerFormattedKey(target, name, description, KeyFormatJSON, defaultValue)
+}
+
+// RegisterUint64Key registers data function with GraphML instance which holds unsigned 64-bit integer values. The
+// key has long type, thus the values are seen as plain long values by consumers not aware of KeyFormatUint64 format,
+// and parsed into uint64 when attributes are requested. The default value must be either nil or non-negative integer.
+func (gml *GraphML) RegisterUint64Key(target KeyForElement, name, description string, defaultValue interface{}) (*Key, error) {
+	if defaultValue != nil {
+		uVal, ok := unsignedValue(defaultValue)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("default value has wrong data type when %s expected: %v", KeyFormatUint64, defaultValue))
+		}
+		defaultValue = uVal
+	}
+	return gml.RegisterKey(target, name, description, reflect.Uint64, defaultValue)
+}
+
+// registerFormattedKey registers string key with given format of values
+func (gml *GraphML) registerFormattedKey(target KeyForElement, name, description, format string, defaultValue interface{}) (*Key, error) {
+	if format == KeyFormatJSON && defaultValue != nil {
+		data, err := json.Marshal(defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		defaultValue = string(data)
+	} else if defaultValue != nil && valueFormat(defaultValue) != format {
+		return nil, errors.New(fmt.Sprintf("default value has wrong data type when %s expected: %T", format, defaultValue))
+	}
+	key, err := gml.RegisterKey(target, name, description, reflect.String, defaultValue)
+	if err != nil {
+		return nil, err
+	}
+	key.Format = format
+	return key, nil
+}
+
+// valueFormat returns the format of string key holding values of the same type as given value, or empty string if
+// values of this type have no special format
+func valueFormat(value interface{}) string {
+	switch value.(type) {
+	case time.Time:
+		return KeyFormatTime
+	case *big.Int:
+		return KeyFormatBigInt
+	case *big.Float:
+		return KeyFormatBigFloat
+	default:
+		return ""
+	}
+}
+
 func (gml *GraphML) nextKeyId() string {
 	count := len(gml.Keys)
 	var id string
@@ -318,7 +650,7 @@ func (gml *GraphML) nextKeyId() string {
 // Returns error if key is not found in target element.
 func (gml *GraphML) RemoveKeyByName(target KeyForElement, name string) error {
 	if key := gml.GetKey(name, target); key == nil {
-		return errors.New("key no found")
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
 	} else {
 		return gml.RemoveKey(key)
 	}
@@ -338,27 +670,35 @@ func (gml *GraphML) RemoveKey(key *Key) error {
 		}
 	}
 	if !found {
-		return errors.New("key not found")
+		return ErrKeyNotFound
 	}
 	gml.Keys = append(gml.Keys[:i], gml.Keys[i+1:]...)
 	delete(gml.keysById, key.ID)
-	delete(gml.keysByIdentifier, keyIdentifier(key.Name, key.Target))
-	if key.Target == KeyForAll || key.Target == KeyForGraphML {
+	defer gml.touch()
+	for _, target := range key.targetList() {
+		delete(gml.keysByIdentifier, keyIdentifier(key.Name, target))
+	}
+	if key.appliesTo(KeyForGraphML) {
 		gml.RemoveAttribute(key.ID)
 	}
 	if key.Target == KeyForGraphML {
 		return nil
 	}
-	for _, graph := range gml.Graphs {
-		if key.Target == KeyForAll || key.Target == KeyForGraph {
+	for _, graph := range gml.allGraphs() {
+		if key.appliesTo(KeyForGraph) {
 			graph.RemoveAttribute(key.ID)
 		}
-		if key.Target == KeyForAll || key.Target == KeyForNode {
+		if key.appliesTo(KeyForNode) {
 			for _, node := range graph.Nodes {
 				node.RemoveAttribute(key.ID)
 			}
 		}
-		if key.Target == KeyForAll || key.Target == KeyForEdge {
+		if key.appliesTo(KeyForPort) {
+			for _, node := range graph.Nodes {
+				removePortsAttribute(node.Ports, key.ID)
+			}
+		}
+		if key.appliesTo(KeyForEdge) {
 			for _, edge := range graph.Edges {
 				edge.RemoveAttribute(key.ID)
 			}
@@ -367,6 +707,14 @@ func (gml *GraphML) RemoveKey(key *Key) error {
 	return nil
 }
 
+// KeyByID looks for registered key with specified ID. Returns found Key or nil.
+func (gml *GraphML) KeyByID(id string) *Key {
+	if key, ok := gml.keysById[id]; ok {
+		return key
+	}
+	return nil
+}
+
 // GetKey looks for registered keys with specified name for a given target element. If specific target has no
 // registered key then common target (KeyForAll) will be checked next. Returns Key (either specific or common) or nil.
 func (gml *GraphML) GetKey(name string, target KeyForElement) *Key {
@@ -380,8 +728,81 @@ func (gml *GraphML) GetKey(name string, target KeyForElement) *Key {
 	return nil
 }
 
+// KeysForElement returns the keys applicable to the given target element, including the common ones (KeyForAll),
+// in the order of their registration
+func (gml *GraphML) KeysForElement(target KeyForElement) []*Key {
+	return keysForElement(gml.Keys, target)
+}
+
+// AttributeNamesForTarget returns sorted names of all data-functions declared for the given target element, including
+// the common ones (KeyForAll). The returned names reflect the registered keys and not the values populated
+// in any specific element.
+func (gml *GraphML) AttributeNamesForTarget(target KeyForElement) []string {
+	names := gml.OrderedAttributeNames(target)
+	sort.Strings(names)
+	return names
+}
+
+// OrderedAttributeNames returns names of all data-functions declared for the given target element, including
+// the common ones (KeyForAll), in the order of declaration of their keys. The keys without name are listed by their
+// IDs in the same way as they are included into attributes maps.
+func (gml *GraphML) OrderedAttributeNames(target KeyForElement) []string {
+	unique := make(map[string]bool)
+	names := make([]string, 0)
+	for _, k := range keysForElement(gml.Keys, target) {
+		if name := k.attributeName(); !unique[name] {
+			unique[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Counts returns the number of graphs, nodes, edges, and keys in this GraphML. The graphs nested into nodes are
+// counted as well, and the numbers of nodes and edges are aggregated across all graphs.
+func (gml *GraphML) Counts() (graphs, nodes, edges, keys int) {
+	all := gml.allGraphs()
+	for _, gr := range all {
+		nodes += gr.NodeCount()
+		edges += gr.EdgeCount()
+	}
+	return len(all), nodes, edges, len(gml.Keys)
+}
+
 // AddGraph creates new Graph and add it to the root GraphML
 func (gml *GraphML) AddGraph(description string, edgeDefault EdgeDirection, attributes map[string]interface{}) (graph *Graph, err error) {
+	if graph, err = gml.newGraph(description, edgeDefault, attributes); err != nil {
+		return nil, err
+	}
+	// store graph in parent
+	gml.Graphs = append(gml.Graphs, graph)
+	gml.linkGraph(graph)
+	gml.touch()
+	return graph, nil
+}
+
+// AddGraph creates new Graph nested into this node, thus making this node hierarchical. The IDs of nested graphs are
+// unique within the GraphML document.
+func (n *Node) AddGraph(description string, edgeDefault EdgeDirection, attributes map[string]interface{}) (graph *Graph, err error) {
+	gml := n.graph.parent
+	if graph, err = gml.newGraph(description, edgeDefault, attributes); err != nil {
+		return nil, err
+	}
+	// store graph in parent node
+	graph.parentNode = n
+	n.Graphs = append(n.Graphs, graph)
+	gml.linkGraph(graph)
+	gml.touch()
+	return graph, nil
+}
+
+// ParentNode returns the node holding this graph if it is nested, or nil for the top level graph
+func (gr *Graph) ParentNode() *Node {
+	return gr.parentNode
+}
+
+// newGraph creates new Graph within this GraphML with provided additional attributes and description
+func (gml *GraphML) newGraph(description string, edgeDefault EdgeDirection, attributes map[string]interface{}) (graph *Graph, err error) {
 	var edgeDirection string
 	switch edgeDefault {
 	case EdgeDirectionDirected:
@@ -389,7 +810,7 @@ func (gml *GraphML) AddGraph(description string, edgeDefault EdgeDirection, attr
 	case EdgeDirectionUndirected:
 		edgeDirection = edgeDirectionUndirected
 	default:
-		return nil, errors.New("default edge direction must be provided")
+		return nil, ErrNoEdgeDirection
 	}
 
 	id := gml.nextGraphId()
@@ -402,38 +823,168 @@ func (gml *GraphML) AddGraph(description string, edgeDefault EdgeDirection, attr
 		parent:         gml,
 		nodesMap:       make(map[string]*Node),
 		edgesMap:       make(map[string]*Edge),
+		edgesById:      make(map[string]*Edge),
 		edgesDirection: edgeDefault,
 	}
 	// add attributes
 	if graph.Data, err = gml.createDataAttributes(attributes, KeyForGraph); err != nil {
 		return nil, err
 	}
-
-	// store graph in parent
-	gml.Graphs = append(gml.Graphs, graph)
 	return graph, nil
 }
 
+// WalkNodes calls provided function for each node of all graphs of this GraphML, including the graphs nested into
+// nodes, in order of their appearance in the document. The walk stops at the first error returned by function.
+func (gml *GraphML) WalkNodes(fn func(g *Graph, n *Node) error) error {
+	for _, gr := range gml.allGraphs() {
+		for _, n := range gr.Nodes {
+			if err := fn(gr, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WalkEdges calls provided function for each edge of all graphs of this GraphML, including the graphs nested into
+// nodes, in order of their appearance in the document. The walk stops at the first error returned by function.
+func (gml *GraphML) WalkEdges(fn func(g *Graph, e *Edge) error) error {
+	for _, gr := range gml.allGraphs() {
+		for _, e := range gr.Edges {
+			if err := fn(gr, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// allGraphs returns all graphs of this GraphML including the graphs nested into nodes in order of their appearance
+// in the document
+func (gml *GraphML) allGraphs() []*Graph {
+	res := make([]*Graph, 0, len(gml.Graphs))
+	var collect func(graphs []*Graph)
+	collect = func(graphs []*Graph) {
+		for _, gr := range graphs {
+			res = append(res, gr)
+			for _, n := range gr.Nodes {
+				collect(n.Graphs)
+			}
+		}
+	}
+	collect(gml.Graphs)
+	return res
+}
+
+// hasLocators checks whether any graph or node of this GraphML refers external content (see Locator)
+func (gml *GraphML) hasLocators() bool {
+	for _, gr := range gml.allGraphs() {
+		if gr.Locator != nil {
+			return true
+		}
+		for _, n := range gr.Nodes {
+			if n.Locator != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasFormattedKeys checks whether any key of this GraphML has the format of values (see Key.Format)
+func (gml *GraphML) hasFormattedKeys() bool {
+	for _, k := range gml.Keys {
+		if k.Format != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// linkGraph links given graph with this GraphML and stores it in the graphs map
+func (gml *GraphML) linkGraph(graph *Graph) {
+	graph.parent = gml
+	if gml.graphsById == nil {
+		gml.graphsById = make(map[string]*Graph)
+	}
+	gml.graphsById[graph.ID] = graph
+}
+
+// unlinkGraph removes given graph and the graphs nested into its nodes from the graphs map
+func (gml *GraphML) unlinkGraph(graph *Graph) {
+	if gml.graphsById[graph.ID] == graph {
+		delete(gml.graphsById, graph.ID)
+	}
+	for _, n := range graph.Nodes {
+		for _, nested := range n.Graphs {
+			gml.unlinkGraph(nested)
+		}
+	}
+}
+
+// RemoveGraph removes the graph with given ID from the GraphML, or from its parent node if the graph is nested.
+// The graphs nested into the nodes of removed graph are removed as well. Returns error if graph not found.
+func (gml *GraphML) RemoveGraph(id string) error {
+	graph := gml.GetGraph(id)
+	if graph == nil {
+		return fmt.Errorf("%w: %s", ErrGraphNotFound, id)
+	}
+	removeGraph := func(graphs []*Graph) []*Graph {
+		for i, gr := range graphs {
+			if gr == graph {
+				return append(graphs[:i], graphs[i+1:]...)
+			}
+		}
+		return graphs
+	}
+	if graph.parentNode != nil {
+		graph.parentNode.Graphs = removeGraph(graph.parentNode.Graphs)
+		graph.parentNode = nil
+	} else {
+		gml.Graphs = removeGraph(gml.Graphs)
+	}
+	gml.unlinkGraph(graph)
+	gml.touch()
+	return nil
+}
+
+// GetGraph method to test if graph with given id exists. If graph exists it will be returned, otherwise nil returned
+func (gml *GraphML) GetGraph(id string) *Graph {
+	if graph, ok := gml.graphsById[id]; ok {
+		return graph
+	}
+	return nil
+}
+
 func (gml *GraphML) nextGraphId() string {
 	count := len(gml.Graphs)
 	var id string
-	for found := true; found; {
+	for found := true; found; _, found = gml.graphsById[id] {
 		id = fmt.Sprintf("g%d", count)
-		found = false
-		for _, g := range gml.Graphs {
-			if g.ID == id {
-				found = true
-				count++
-				break
-			}
-		}
+		count++
 	}
 	return id
 }
 
 // AddNode adds node to the graph with provided additional attributes and description
 func (gr *Graph) AddNode(attributes map[string]interface{}, description string) (node *Node, err error) {
-	id := gr.nextNodeId()
+	return gr.addNode(gr.nextNodeId(), attributes, description)
+}
+
+// AddNodeWithID adds node with given ID to the graph with provided additional attributes and description. Returns
+// error if ID is empty or the node with the same ID already exists in the graph.
+func (gr *Graph) AddNodeWithID(id string, attributes map[string]interface{}, description string) (node *Node, err error) {
+	if id == "" {
+		return nil, errors.New("node ID must be provided")
+	}
+	if gr.GetNode(id) != nil {
+		return nil, errors.New(fmt.Sprintf("node with ID: %s already exists in graph: %s", id, gr.ID))
+	}
+	return gr.addNode(id, attributes, description)
+}
+
+// addNode adds node with given ID to the graph with provided additional attributes and description
+func (gr *Graph) addNode(id string, attributes map[string]interface{}, description string) (node *Node, err error) {
 	node = &Node{
 		ID:          id,
 		Description: description,
@@ -445,12 +996,61 @@ func (gr *Graph) AddNode(attributes map[string]interface{}, description string)
 	}
 
 	// add node
-	node.graph = gr
 	gr.Nodes = append(gr.Nodes, node)
-	gr.nodesMap[node.ID] = node
+	gr.linkNode(node)
+	gr.parent.touch()
 	return node, nil
 }
 
+// NodeSpec The specification of the node to be created by bulk node creation
+type NodeSpec struct {
+	// The additional attributes of the node
+	Attributes map[string]interface{}
+	// The description of the node
+	Description string
+}
+
+// AddNodes adds nodes to the graph according to the provided specifications. The data attributes of all nodes are
+// created before adding any node, thus the graph is left intact if specification of any node is invalid. The keys
+// registered for the attributes of specifications preceding the invalid one are not rolled back. Returns the created
+// nodes in the order of specifications or the error of the first invalid specification with its index.
+func (gr *Graph) AddNodes(entries []NodeSpec) (nodes []*Node, err error) {
+	data := make([][]*Data, len(entries))
+	for i, entry := range entries {
+		if data[i], err = gr.parent.createDataAttributes(entry.Attributes, KeyForNode); err != nil {
+			return nil, fmt.Errorf("failed to create node at index: %d, reason: %w", i, err)
+		}
+	}
+	nodes = make([]*Node, len(entries))
+	for i, entry := range entries {
+		node := &Node{
+			ID:          gr.nextNodeId(),
+			Description: entry.Description,
+			Data:        data[i],
+		}
+		gr.Nodes = append(gr.Nodes, node)
+		gr.linkNode(node)
+		nodes[i] = node
+	}
+	gr.parent.touch()
+	return nodes, nil
+}
+
+// linkNode links given node with this graph and stores it in the nodes map
+func (gr *Graph) linkNode(node *Node) {
+	node.graph = gr
+	gr.nodesMap[node.ID] = node
+	linkPorts(node, node.Ports)
+}
+
+// linkPorts links given ports and their nested ports with provided node
+func linkPorts(node *Node, ports []*Port) {
+	for _, p := range ports {
+		p.node = node
+		linkPorts(node, p.Ports)
+	}
+}
+
 func (gr *Graph) nextNodeId() string {
 	count := len(gr.Nodes)
 	var id string
@@ -461,6 +1061,41 @@ func (gr *Graph) nextNodeId() string {
 	return id
 }
 
+// NodeCount returns the number of nodes in this graph
+func (gr *Graph) NodeCount() int {
+	return len(gr.Nodes)
+}
+
+// EdgeCount returns the number of edges in this graph
+func (gr *Graph) EdgeCount() int {
+	return len(gr.Edges)
+}
+
+// ComputeParseHints computes the parse hints of this graph from its contents, which are emitted as the parse.*
+// attributes of graph element when encoded. The hints are not updated with graph changes, thus they should be
+// computed again after modification.
+func (gr *Graph) ComputeParseHints() {
+	gr.ParseHints = gr.parseHints()
+}
+
+// parseHints computes the parse hints of this graph from its contents. The nodes always precede the edges in the
+// encoded graph.
+func (gr *Graph) parseHints() *ParseHints {
+	res := &ParseHints{
+		Nodes:   len(gr.Nodes),
+		Edges:   len(gr.Edges),
+		Order:   ParseOrderNodesFirst,
+		NodeIDs: ParseNodeIDsCanonical,
+	}
+	for i, n := range gr.Nodes {
+		if n.ID != fmt.Sprintf("n%d", i) {
+			res.NodeIDs = ParseNodeIDsFree
+			break
+		}
+	}
+	return res
+}
+
 // GetNode method to test if node with given id exists. If node exists it will be returned, otherwise nil returned
 func (gr *Graph) GetNode(id string) *Node {
 	if node, ok := gr.nodesMap[id]; ok {
@@ -469,34 +1104,244 @@ func (gr *Graph) GetNode(id string) *Node {
 	return nil
 }
 
-// AddEdge adds edge to the graph which connects two its nodes with provided additional attributes and description
-func (gr *Graph) AddEdge(source, target *Node, attributes map[string]interface{}, edgeDirection EdgeDirection, description string) (edge *Edge, err error) {
-	// test if edge already exists
-	edgeIdentification := edgeIdentifier(source.ID, target.ID)
-	exists := false
-	if _, exists = gr.edgesMap[edgeIdentification]; !exists && (edgeDirection == EdgeDirectionUndirected || gr.edgesDirection == EdgeDirectionUndirected) {
-		// check other direction for undirected edge or graph types
-		edgeIdentification = edgeIdentifier(target.ID, source.ID)
-		_, exists = gr.edgesMap[edgeIdentification]
+// RemoveNode removes node with given ID from the graph along with all edges incident to it. The IDs of the
+// remaining nodes and edges are not changed. Returns error if node not found.
+func (gr *Graph) RemoveNode(id string) error {
+	node := gr.GetNode(id)
+	if node == nil {
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+	}
+	for i, n := range gr.Nodes {
+		if n == node {
+			gr.Nodes = append(gr.Nodes[:i], gr.Nodes[i+1:]...)
+			break
+		}
 	}
-	if exists {
-		return nil, errors.New("edge already added to the graph")
+	delete(gr.nodesMap, id)
+	node.graph = nil
+	for _, nested := range node.Graphs {
+		gr.parent.unlinkGraph(nested)
 	}
 
-	id := gr.nextEdgeId()
-	edge = &Edge{
-		ID:          id,
-		Source:      source.ID,
-		Target:      target.ID,
-		Description: description,
+	edges := gr.Edges[:0]
+	for _, e := range gr.Edges {
+		if e.Source == id || e.Target == id {
+			gr.unlinkEdge(e)
+		} else {
+			edges = append(edges, e)
+		}
 	}
-	switch edgeDirection {
-	case EdgeDirectionDirected:
-		edge.Directed = "true"
-	case EdgeDirectionUndirected:
-		edge.Directed = "false"
-	default:
-		// ignore
+	for i := len(edges); i < len(gr.Edges); i++ {
+		gr.Edges[i] = nil
+	}
+	gr.Edges = edges
+	delete(gr.outEdges, id)
+	delete(gr.inEdges, id)
+	gr.parent.touch()
+	return nil
+}
+
+// OutgoingEdges returns the edges starting at this node in order of their addition, followed by the undirected edges
+// ending at this node. Thus, all incident edges are returned for undirected graph.
+func (n *Node) OutgoingEdges() []*Edge {
+	return adjacentEdges(n.graph.outEdges[n.ID], n.graph.inEdges[n.ID])
+}
+
+// IncomingEdges returns the edges ending at this node in order of their addition, followed by the undirected edges
+// starting at this node. Thus, all incident edges are returned for undirected graph.
+func (n *Node) IncomingEdges() []*Edge {
+	return adjacentEdges(n.graph.inEdges[n.ID], n.graph.outEdges[n.ID])
+}
+
+// Degree returns the number of edges incident to this node regardless of their direction. The self-loop adds two
+// to the degree (see Graph.DegreeDistribution).
+func (n *Node) Degree() int {
+	return len(n.graph.outEdges[n.ID]) + len(n.graph.inEdges[n.ID])
+}
+
+// InDegree returns the number of edges entering this node, i.e., the number of IncomingEdges
+func (n *Node) InDegree() int {
+	return len(n.IncomingEdges())
+}
+
+// OutDegree returns the number of edges leaving this node, i.e., the number of OutgoingEdges
+func (n *Node) OutDegree() int {
+	return len(n.OutgoingEdges())
+}
+
+// adjacentEdges returns the list of given edges followed by the undirected edges from the reverse list, except
+// self-loops which are already listed
+func adjacentEdges(edges, reverse []*Edge) []*Edge {
+	res := make([]*Edge, 0, len(edges))
+	res = append(res, edges...)
+	for _, e := range reverse {
+		if !e.directed() && e.Source != e.Target {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// removeEdge removes given edge from the list of edges
+func removeEdge(edges []*Edge, edge *Edge) []*Edge {
+	for i, e := range edges {
+		if e == edge {
+			return append(edges[:i:i], edges[i+1:]...)
+		}
+	}
+	return edges
+}
+
+// Path returns the hierarchical path of this node from the root of GraphML document in form "graphID/nodeID". The path
+// of the node within nested graph is prefixed by the path of the node holding this graph, e.g., "g0/n0/g1/n1".
+func (n *Node) Path() string {
+	path := n.graph.ID + pathSeparator + n.ID
+	if n.graph.parentNode != nil {
+		path = n.graph.parentNode.Path() + pathSeparator + path
+	}
+	return path
+}
+
+// NodeByPath looks for the node by its hierarchical path from the root of GraphML document (see Node.Path).
+// Returns found node or nil.
+func (gml *GraphML) NodeByPath(path string) *Node {
+	parts := strings.Split(path, pathSeparator)
+	if len(parts) < 2 || len(parts)%2 != 0 {
+		return nil
+	}
+	var node *Node
+	graphs := gml.Graphs
+	for i := 0; i < len(parts); i += 2 {
+		var graph *Graph
+		for _, gr := range graphs {
+			if gr.ID == parts[i] {
+				graph = gr
+				break
+			}
+		}
+		if graph == nil {
+			return nil
+		}
+		if node = graph.GetNode(parts[i+1]); node == nil {
+			return nil
+		}
+		graphs = node.Graphs
+	}
+	return node
+}
+
+// GenerateDescriptions sets the description of each node of this graph generated from the provided template with
+// references to the node attributes in form {name}, e.g., "{name} - {dept}". The "{id}" reference is replaced by
+// the ID of the node unless it has the attribute with such name. If errorOnMissing is set then error is returned
+// when node has neither data for referenced attribute nor the default value of its key, otherwise, missing attributes
+// are rendered as empty strings. The descriptions of nodes are left intact if error returned.
+func (gr *Graph) GenerateDescriptions(template string, errorOnMissing bool) error {
+	descriptions := make([]string, len(gr.Nodes))
+	for i, n := range gr.Nodes {
+		attrs, defaulted, err := n.GetAttributesWithSource()
+		if err != nil {
+			return err
+		}
+		if _, ok := attrs["id"]; !ok {
+			attrs["id"] = n.ID
+		}
+		var sb strings.Builder
+		rest := template
+		for {
+			start := strings.Index(rest, "{")
+			if start < 0 {
+				break
+			}
+			end := strings.Index(rest[start:], "}")
+			if end < 0 {
+				break
+			}
+			sb.WriteString(rest[:start])
+			name := rest[start+1 : start+end]
+			value, ok := attrs[name]
+			if ok && defaulted[name] && value == "" {
+				// the empty string filled for the string key without default value
+				ok = false
+			}
+			if ok {
+				sb.WriteString(fmt.Sprint(value))
+			} else if errorOnMissing {
+				return errors.New(fmt.Sprintf("node: %s has no attribute: %s", n.ID, name))
+			}
+			rest = rest[start+end+1:]
+		}
+		sb.WriteString(rest)
+		descriptions[i] = sb.String()
+	}
+	for i, n := range gr.Nodes {
+		n.Description = descriptions[i]
+	}
+	return nil
+}
+
+// AddEdge adds edge to the graph which connects two its nodes with provided additional attributes and description
+func (gr *Graph) AddEdge(source, target *Node, attributes map[string]interface{}, edgeDirection EdgeDirection, description string) (edge *Edge, err error) {
+	return gr.AddEdgeWithPorts(source, "", target, "", attributes, edgeDirection, description)
+}
+
+// AddEdgeWithPorts adds edge to the graph which connects the ports with given names of two its nodes with provided
+// additional attributes and description. The empty port name means that edge is attached to the node itself.
+// The edges between different ports of the same nodes are distinct. Returns error if port not found, or if the edge
+// connecting the same ports already exists and AllowMultiEdges flag of the graph is not set.
+func (gr *Graph) AddEdgeWithPorts(source *Node, sourcePort string, target *Node, targetPort string, attributes map[string]interface{}, edgeDirection EdgeDirection, description string) (edge *Edge, err error) {
+	return gr.addEdge(gr.nextEdgeId(), source, sourcePort, target, targetPort, attributes, edgeDirection, description)
+}
+
+// AddEdgeWithID adds edge with given ID to the graph which connects two its nodes with provided additional attributes
+// and description. Returns error if ID is empty or the edge with the same ID already exists in the graph (see AddEdge).
+func (gr *Graph) AddEdgeWithID(id string, source, target *Node, attributes map[string]interface{}, edgeDirection EdgeDirection, description string) (edge *Edge, err error) {
+	if id == "" {
+		return nil, errors.New("edge ID must be provided")
+	}
+	if gr.GetEdgeByID(id) != nil {
+		return nil, errors.New(fmt.Sprintf("edge with ID: %s already exists in graph: %s", id, gr.ID))
+	}
+	return gr.addEdge(id, source, "", target, "", attributes, edgeDirection, description)
+}
+
+// addEdge adds edge with given ID to the graph which connects the ports with given names of two its nodes
+// (see AddEdgeWithPorts)
+func (gr *Graph) addEdge(id string, source *Node, sourcePort string, target *Node, targetPort string, attributes map[string]interface{}, edgeDirection EdgeDirection, description string) (edge *Edge, err error) {
+	for _, p := range []struct {
+		node *Node
+		name string
+	}{{source, sourcePort}, {target, targetPort}} {
+		if p.name != "" && p.node.GetPort(p.name) == nil {
+			return nil, errors.New(fmt.Sprintf("port: %s not found at node: %s", p.name, p.node.ID))
+		}
+	}
+	// test if edge already exists
+	edgeIdentification := portEdgeIdentifier(source.ID, sourcePort, target.ID, targetPort)
+	exists := false
+	if _, exists = gr.edgesMap[edgeIdentification]; !exists && (edgeDirection == EdgeDirectionUndirected || gr.edgesDirection == EdgeDirectionUndirected) {
+		// check other direction for undirected edge or graph types
+		edgeIdentification = portEdgeIdentifier(target.ID, targetPort, source.ID, sourcePort)
+		_, exists = gr.edgesMap[edgeIdentification]
+	}
+	if exists && !gr.AllowMultiEdges {
+		return nil, ErrEdgeAlreadyAdded
+	}
+
+	edge = &Edge{
+		ID:             id,
+		Source:         source.ID,
+		Target:         target.ID,
+		SourcePortName: sourcePort,
+		TargetPortName: targetPort,
+		Description:    description,
+	}
+	switch edgeDirection {
+	case EdgeDirectionDirected:
+		edge.Directed = "true"
+	case EdgeDirectionUndirected:
+		edge.Directed = "false"
+	default:
+		// ignore
 	}
 
 	// add attributes
@@ -505,39 +1350,163 @@ func (gr *Graph) AddEdge(source, target *Node, attributes map[string]interface{}
 	}
 
 	// add edge
-	edge.graph = gr
 	gr.Edges = append(gr.Edges, edge)
-	gr.edgesMap[edgeIdentifier(source.ID, target.ID)] = edge
+	gr.linkEdge(edge)
+	gr.parent.touch()
 
 	return edge, nil
 }
 
-func (gr *Graph) nextEdgeId() string {
-	count := len(gr.Edges)
-	var id string
-	for found := true; found; {
-		id = fmt.Sprintf("e%d", count)
-		found = false
+// linkEdge links given edge with this graph and stores it in the edges map unless the map already holds parallel edge
+func (gr *Graph) linkEdge(edge *Edge) {
+	edge.graph = gr
+	if _, ok := gr.edgesMap[edge.identifier()]; !ok {
+		gr.edgesMap[edge.identifier()] = edge
+	}
+	if gr.edgesById == nil {
+		gr.edgesById = make(map[string]*Edge)
+	}
+	if _, ok := gr.edgesById[edge.ID]; !ok {
+		gr.edgesById[edge.ID] = edge
+	}
+	if gr.outEdges == nil {
+		gr.outEdges = make(map[string][]*Edge)
+		gr.inEdges = make(map[string][]*Edge)
+	}
+	gr.outEdges[edge.Source] = append(gr.outEdges[edge.Source], edge)
+	gr.inEdges[edge.Target] = append(gr.inEdges[edge.Target], edge)
+}
+
+// unlinkEdge removes given edge from the edges map and unlinks it from this graph. The next parallel edge, if any,
+// takes its place in the edges map.
+func (gr *Graph) unlinkEdge(edge *Edge) {
+	identifier := edge.identifier()
+	if gr.edgesMap[identifier] == edge {
+		delete(gr.edgesMap, identifier)
 		for _, e := range gr.Edges {
-			if e.ID == id {
-				found = true
-				count++
+			if e != edge && e.graph == gr && e.identifier() == identifier {
+				gr.edgesMap[identifier] = e
 				break
 			}
 		}
 	}
+	if gr.edgesById[edge.ID] == edge {
+		delete(gr.edgesById, edge.ID)
+	}
+	gr.outEdges[edge.Source] = removeEdge(gr.outEdges[edge.Source], edge)
+	gr.inEdges[edge.Target] = removeEdge(gr.inEdges[edge.Target], edge)
+	edge.graph = nil
+}
+
+// AddEdgeByID adds edge to the graph which connects two its nodes with given IDs. If createMissing is set then the nodes
+// not present in the graph are created with given IDs (see AddNodeWithID), otherwise error is returned for missing
+// nodes. The nodes created by this call are removed if the edge can not be added.
+func (gr *Graph) AddEdgeByID(sourceID, targetID string, attributes map[string]interface{}, edgeDirection EdgeDirection, description string, createMissing bool) (edge *Edge, err error) {
+	nodes := make([]*Node, 2)
+	created := make([]string, 0, 2)
+	defer func() {
+		if err != nil {
+			for _, id := range created {
+				_ = gr.RemoveNode(id)
+			}
+		}
+	}()
+	for i, id := range []string{sourceID, targetID} {
+		if nodes[i] = gr.GetNode(id); nodes[i] != nil {
+			continue
+		}
+		if !createMissing {
+			return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+		}
+		if nodes[i], err = gr.AddNodeWithID(id, nil, ""); err != nil {
+			return nil, err
+		}
+		created = append(created, id)
+	}
+	return gr.AddEdge(nodes[0], nodes[1], attributes, edgeDirection, description)
+}
+
+func (gr *Graph) nextEdgeId() string {
+	count := len(gr.Edges)
+	var id string
+	for found := true; found; _, found = gr.edgesById[id] {
+		id = fmt.Sprintf("e%d", count)
+		count++
+	}
 	return id
 }
 
-// GetEdge method to test if edge exists between given nodes. If edge exists it will be returned, otherwise nil returned
+// GetEdge method to test if edge exists between given nodes. If edge exists it will be returned, otherwise nil returned.
+// The undirected edge is found regardless of the order of provided IDs. The edge not attached to ports is preferred,
+// otherwise the first added edge is returned if there are parallel edges (see GetEdges).
 func (gr *Graph) GetEdge(sourceId, targetId string) *Edge {
-	edgeIdentification := edgeIdentifier(sourceId, targetId)
-	if edge, ok := gr.edgesMap[edgeIdentification]; ok {
+	if edge, ok := gr.edgesMap[edgeIdentifier(sourceId, targetId)]; ok {
+		return edge
+	}
+	// look for edge attached to ports
+	for _, e := range gr.outEdges[sourceId] {
+		if e.Target == targetId {
+			return e
+		}
+	}
+	// look for undirected edge in reverse direction
+	for _, e := range gr.outEdges[targetId] {
+		if e.Target == sourceId && e.SourcePortName == "" && e.TargetPortName == "" && !e.directed() {
+			return e
+		}
+	}
+	return nil
+}
+
+// GetEdgeByID method to test if edge with given ID exists. If edge exists it will be returned, otherwise nil returned
+func (gr *Graph) GetEdgeByID(id string) *Edge {
+	if edge, ok := gr.edgesById[id]; ok {
 		return edge
 	}
 	return nil
 }
 
+// GetEdges returns all edges from the node with sourceId to the node with targetId in order of their addition,
+// including the edges attached to the ports of nodes. The list is empty if nodes are not connected.
+func (gr *Graph) GetEdges(sourceId, targetId string) []*Edge {
+	res := make([]*Edge, 0)
+	for _, e := range gr.Edges {
+		if e.Source == sourceId && e.Target == targetId {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// GetEdgeWithPorts method to test if edge connecting ports with given names of the nodes with given IDs exists. The
+// empty port name means that edge is attached to the node itself. If edge exists it will be returned, otherwise nil.
+func (gr *Graph) GetEdgeWithPorts(sourceId, sourcePort, targetId, targetPort string) *Edge {
+	if edge, ok := gr.edgesMap[portEdgeIdentifier(sourceId, sourcePort, targetId, targetPort)]; ok {
+		return edge
+	}
+	return nil
+}
+
+// Direction returns the effective direction of this edge, i.e., either the direction set explicitly by its directed
+// attribute or the default edge direction of its graph. The edges of graph without default edge direction are directed.
+func (e *Edge) Direction() EdgeDirection {
+	switch e.Directed {
+	case "true", "1":
+		return EdgeDirectionDirected
+	case "false", "0":
+		return EdgeDirectionUndirected
+	}
+	if e.graph != nil && e.graph.edgesDirection == EdgeDirectionUndirected {
+		return EdgeDirectionUndirected
+	}
+	return EdgeDirectionDirected
+}
+
+// directed returns true if this edge is directed either explicitly or by the default edge direction of its graph
+func (e *Edge) directed() bool {
+	return e.Direction() == EdgeDirectionDirected
+}
+
 // SourceNode method to get the source node struct. If it exists it will be returned, otherwise nil returned
 func (e *Edge) SourceNode() *Node {
 	return e.graph.GetNode(e.Source)
@@ -548,6 +1517,100 @@ func (e *Edge) TargetNode() *Node {
 	return e.graph.GetNode(e.Target)
 }
 
+// SourcePort returns the port of the source node this edge is attached to, or nil if edge is not attached to any port
+func (e *Edge) SourcePort() *Port {
+	if e.SourcePortName == "" {
+		return nil
+	}
+	if node := e.SourceNode(); node != nil {
+		return node.GetPort(e.SourcePortName)
+	}
+	return nil
+}
+
+// TargetPort returns the port of the target node this edge is attached to, or nil if edge is not attached to any port
+func (e *Edge) TargetPort() *Port {
+	if e.TargetPortName == "" {
+		return nil
+	}
+	if node := e.TargetNode(); node != nil {
+		return node.GetPort(e.TargetPortName)
+	}
+	return nil
+}
+
+// GetPort looks for the port of this node with given name, including nested ports. Returns found port or nil.
+func (n *Node) GetPort(name string) *Port {
+	return findPort(n.Ports, name)
+}
+
+// AddPort adds port with given name to this node with provided additional attributes and description. Returns error
+// if the node already has port with the same name.
+func (n *Node) AddPort(name string, attributes map[string]interface{}, description string) (*Port, error) {
+	port, err := n.newPort(name, attributes, description)
+	if err != nil {
+		return nil, err
+	}
+	n.Ports = append(n.Ports, port)
+	return port, nil
+}
+
+// AddPort adds port with given name nested into this port with provided additional attributes and description.
+// Returns error if the node of this port already has port with the same name.
+func (p *Port) AddPort(name string, attributes map[string]interface{}, description string) (*Port, error) {
+	port, err := p.node.newPort(name, attributes, description)
+	if err != nil {
+		return nil, err
+	}
+	p.Ports = append(p.Ports, port)
+	return port, nil
+}
+
+// newPort creates new port of this node with provided additional attributes and description
+func (n *Node) newPort(name string, attributes map[string]interface{}, description string) (port *Port, err error) {
+	if n.GetPort(name) != nil {
+		return nil, errors.New(fmt.Sprintf("port: %s already exists at node: %s", name, n.ID))
+	}
+	port = &Port{
+		Name:        name,
+		Description: description,
+		node:        n,
+	}
+	gml := n.graph.parent
+	if port.Data, err = gml.createDataAttributes(attributes, KeyForPort); err != nil {
+		return nil, err
+	}
+	gml.touch()
+	return port, nil
+}
+
+// GetAttributes returns data attributes map associated with Port
+func (p *Port) GetAttributes() (map[string]interface{}, error) {
+	return attributesForData(p.Data, KeyForPort, p.node.graph.parent)
+}
+
+// removePortsAttribute removes the attribute associated with the given key ID from the data of provided ports and
+// their nested ports
+func removePortsAttribute(ports []*Port, key string) {
+	for _, p := range ports {
+		p.Data = removeAttributeFromData(p.Data, key)
+		removePortsAttribute(p.Ports, key)
+	}
+}
+
+// findPort looks for the port with given name among provided ports and their nested ports
+func findPort(ports []*Port, name string) *Port {
+	for _, p := range ports {
+		if p.Name == name {
+			return p
+		}
+		if nested := findPort(p.Ports, name); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
 // RemoveAttribute removes the attribute associated with the given key ID from
 // the data of this GraphML.
 func (gml *GraphML) RemoveAttribute(key string) {
@@ -572,6 +1635,38 @@ func (e *Edge) RemoveAttribute(key string) {
 	e.Data = removeAttributeFromData(e.Data, key)
 }
 
+// RemoveAttributeByName removes the attribute with given name from the data of this GraphML. The key of attribute is
+// resolved for GraphML element (see GetKey).
+func (gml *GraphML) RemoveAttributeByName(keyName string) {
+	gml.Data = gml.removeAttributeByName(gml.Data, KeyForGraphML, keyName)
+}
+
+// RemoveAttributeByName removes the attribute with given name from the data of this graph. The key of attribute is
+// resolved for graph element (see GetKey).
+func (gr *Graph) RemoveAttributeByName(keyName string) {
+	gr.Data = gr.parent.removeAttributeByName(gr.Data, KeyForGraph, keyName)
+}
+
+// RemoveAttributeByName removes the attribute with given name from the data of this node. The key of attribute is
+// resolved for node element (see GetKey).
+func (n *Node) RemoveAttributeByName(keyName string) {
+	n.Data = n.graph.parent.removeAttributeByName(n.Data, KeyForNode, keyName)
+}
+
+// RemoveAttributeByName removes the attribute with given name from the data of this edge. The key of attribute is
+// resolved for edge element (see GetKey).
+func (e *Edge) RemoveAttributeByName(keyName string) {
+	e.Data = e.graph.parent.removeAttributeByName(e.Data, KeyForEdge, keyName)
+}
+
+// removeAttributeByName removes the attribute with given name from the given data of the element with given target.
+func (gml *GraphML) removeAttributeByName(data []*Data, target KeyForElement, keyName string) []*Data {
+	if key := gml.GetKey(keyName, target); key != nil {
+		return removeAttributeFromData(data, key.ID)
+	}
+	return data
+}
+
 // removeAttributeFromData removes the attribute associated with the given key ID from
 // the given data.
 func removeAttributeFromData(data []*Data, key string) []*Data {
@@ -585,38 +1680,49 @@ func removeAttributeFromData(data []*Data, key string) []*Data {
 	return data
 }
 
-// SetAttribute sets the value of the attribute associated with the given key ID
-// in the data of this GraphML.
-func (gml *GraphML) SetAttribute(key string, val interface{}) (err error) {
-	gml.Data, err = gml.setAttributeForData(gml.Data, KeyForGraphML, key, val)
+// SetAttribute sets the value of the attribute with given name in the data of this GraphML, either updating existing
+// data or appending new one. If no key registered for the name, then new key is registered using the kind of value.
+// Returns error if the type of value is incompatible with the type of registered key.
+func (gml *GraphML) SetAttribute(name string, val interface{}) (err error) {
+	if gml.Data, err = gml.setAttributeForData(gml.Data, KeyForGraphML, name, val); err == nil {
+		gml.touch()
+	}
 	return
 }
 
-// SetAttribute sets the value of the attribute associated with the given key ID
-// in the data of this graph.
-func (gr *Graph) SetAttribute(key string, val interface{}) (err error) {
-	gr.Data, err = gr.parent.setAttributeForData(gr.Data, KeyForGraph, key, val)
+// SetAttribute sets the value of the attribute with given name in the data of this graph, either updating existing
+// data or appending new one. If no key registered for the name, then new key is registered using the kind of value.
+// Returns error if the type of value is incompatible with the type of registered key.
+func (gr *Graph) SetAttribute(name string, val interface{}) (err error) {
+	if gr.Data, err = gr.parent.setAttributeForData(gr.Data, KeyForGraph, name, val); err == nil {
+		gr.parent.touch()
+	}
 	return
 }
 
-// SetAttribute sets the value of the attribute associated with the given key ID
-// in the data of this node.
-func (n *Node) SetAttribute(key string, val interface{}) (err error) {
-	n.Data, err = n.graph.parent.setAttributeForData(n.Data, KeyForNode, key, val)
+// SetAttribute sets the value of the attribute with given name in the data of this node, either updating existing
+// data or appending new one. If no key registered for the name, then new key is registered using the kind of value.
+// Returns error if the type of value is incompatible with the type of registered key.
+func (n *Node) SetAttribute(name string, val interface{}) (err error) {
+	if n.Data, err = n.graph.parent.setAttributeForData(n.Data, KeyForNode, name, val); err == nil {
+		n.graph.parent.touch()
+	}
 	return
 }
 
-// SetAttribute sets the value of the attribute associated with the given key ID
-// in the data of this edge.
-func (e *Edge) SetAttribute(key string, val interface{}) (err error) {
-	e.Data, err = e.graph.parent.setAttributeForData(e.Data, KeyForEdge, key, val)
+// SetAttribute sets the value of the attribute with given name in the data of this edge, either updating existing
+// data or appending new one. If no key registered for the name, then new key is registered using the kind of value.
+// Returns error if the type of value is incompatible with the type of registered key.
+func (e *Edge) SetAttribute(name string, val interface{}) (err error) {
+	if e.Data, err = e.graph.parent.setAttributeForData(e.Data, KeyForEdge, name, val); err == nil {
+		e.graph.parent.touch()
+	}
 	return
 }
 
-// setAttributeForData sets the value of the attribute associated with
-// the given key ID in the given data.
-func (gml *GraphML) setAttributeForData(data []*Data, target KeyForElement, key string, val interface{}) ([]*Data, error) {
-	newData, err := gml.createDataAttribute(val, key, target)
+// setAttributeForData sets the value of the attribute with given name in the given data.
+func (gml *GraphML) setAttributeForData(data []*Data, target KeyForElement, name string, val interface{}) ([]*Data, error) {
+	newData, err := gml.createDataAttribute(val, name, target)
 	if err != nil {
 		return data, err
 	}
@@ -629,6 +1735,130 @@ func (gml *GraphML) setAttributeForData(data []*Data, target KeyForElement, key
 	return append(data, newData), nil
 }
 
+// forEachData invokes provided function for each data element of this GraphML and of all its elements
+func (gml *GraphML) forEachData(fn func(d *Data)) {
+	for _, d := range gml.Data {
+		fn(d)
+	}
+	for _, gr := range gml.allGraphs() {
+		for _, d := range gr.Data {
+			fn(d)
+		}
+		for _, n := range gr.Nodes {
+			for _, d := range n.Data {
+				fn(d)
+			}
+			forEachPortData(n.Ports, fn)
+		}
+		for _, e := range gr.Edges {
+			for _, d := range e.Data {
+				fn(d)
+			}
+		}
+	}
+}
+
+// forEachPortData calls provided function for each data of given ports and their nested ports
+func forEachPortData(ports []*Port, fn func(d *Data)) {
+	for _, p := range ports {
+		for _, d := range p.Data {
+			fn(d)
+		}
+		forEachPortData(p.Ports, fn)
+	}
+}
+
+// OrphanRef The reference to the data element whose key is not registered with GraphML
+type OrphanRef struct {
+	// The type of element holding the data
+	Element KeyForElement
+	// The ID of graph holding the element, empty for the data of GraphML
+	GraphID string
+	// The ID of element holding the data, equal to GraphID for the data of the graph and empty for the data of GraphML
+	ElementID string
+	// The orphaned data
+	Data *Data
+}
+
+// FindOrphanedData looks for the data elements referring keys which are not registered with this GraphML, e.g.,
+// removed directly from Keys list instead of RemoveKey. Such data results in error when attributes of the element
+// are requested. Returns references to all found orphaned data.
+func (gml *GraphML) FindOrphanedData() []OrphanRef {
+	res := make([]OrphanRef, 0)
+	collect := func(data []*Data, element KeyForElement, graphID, elementID string) {
+		for _, d := range data {
+			if _, ok := gml.keysById[d.Key]; !ok {
+				res = append(res, OrphanRef{Element: element, GraphID: graphID, ElementID: elementID, Data: d})
+			}
+		}
+	}
+	collect(gml.Data, KeyForGraphML, "", "")
+	for _, gr := range gml.allGraphs() {
+		collect(gr.Data, KeyForGraph, gr.ID, gr.ID)
+		for _, n := range gr.Nodes {
+			collect(n.Data, KeyForNode, gr.ID, n.ID)
+		}
+		for _, e := range gr.Edges {
+			collect(e.Data, KeyForEdge, gr.ID, e.ID)
+		}
+	}
+	return res
+}
+
+// Validate checks the structural invariants of this GraphML: key IDs and graph IDs are unique, node IDs are unique
+// within graph, every data element refers declared key, edges refer existing nodes, and edge default of graphs is
+// either "directed" or "undirected". Returns all found problems, or empty list if GraphML is valid.
+func (gml *GraphML) Validate() []error {
+	res := make([]error, 0)
+	keys := make(map[string]bool, len(gml.Keys))
+	for _, k := range gml.Keys {
+		if keys[k.ID] {
+			res = append(res, errors.New(fmt.Sprintf("duplicate key ID: %s", k.ID)))
+		}
+		keys[k.ID] = true
+	}
+	checkData := func(data []*Data, element KeyForElement, elementID string) {
+		for _, d := range data {
+			if !keys[d.Key] {
+				res = append(res, errors.New(fmt.Sprintf("data of %s: %s refers undeclared key: %s", element, elementID, d.Key)))
+			}
+		}
+	}
+	checkData(gml.Data, KeyForGraphML, "")
+	graphs := make(map[string]bool, len(gml.Graphs))
+	for _, gr := range gml.allGraphs() {
+		if graphs[gr.ID] {
+			res = append(res, errors.New(fmt.Sprintf("duplicate graph ID: %s", gr.ID)))
+		}
+		graphs[gr.ID] = true
+		if gr.EdgeDefault != edgeDirectionDirected && gr.EdgeDefault != edgeDirectionUndirected {
+			res = append(res, errors.New(fmt.Sprintf("wrong edge default: %s of graph: %s", gr.EdgeDefault, gr.ID)))
+		}
+		checkData(gr.Data, KeyForGraph, gr.ID)
+		nodes := make(map[string]bool, len(gr.Nodes))
+		for _, n := range gr.Nodes {
+			if nodes[n.ID] {
+				res = append(res, errors.New(fmt.Sprintf("duplicate node ID: %s in graph: %s", n.ID, gr.ID)))
+			}
+			nodes[n.ID] = true
+			checkData(n.Data, KeyForNode, n.ID)
+			forEachPortData(n.Ports, func(d *Data) {
+				checkData([]*Data{d}, KeyForNode, n.ID)
+			})
+		}
+		for _, e := range gr.Edges {
+			if !nodes[e.Source] {
+				res = append(res, errors.New(fmt.Sprintf("source node: %s of edge: %s not found", e.Source, e.ID)))
+			}
+			if !nodes[e.Target] {
+				res = append(res, errors.New(fmt.Sprintf("target node: %s of edge: %s not found", e.Target, e.ID)))
+			}
+			checkData(e.Data, KeyForEdge, e.ID)
+		}
+	}
+	return res
+}
+
 // GetAttributes return data attributes map associated with GraphML
 func (gml *GraphML) GetAttributes() (map[string]interface{}, error) {
 	return attributesForData(gml.Data, KeyForGraphML, gml)
@@ -639,9 +1869,134 @@ func (gr *Graph) GetAttributes() (map[string]interface{}, error) {
 	return attributesForData(gr.Data, KeyForGraph, gr.parent)
 }
 
-// GetAttributes returns data attributes map associated with Node
+// GetAttributes returns data attributes map associated with Node. If InheritGraphAttributes flag of GraphML is set
+// then the attributes of the parent graph defined by keys for all elements are included, unless the node has
+// explicit data for the same key.
 func (n *Node) GetAttributes() (map[string]interface{}, error) {
-	return attributesForData(n.Data, KeyForNode, n.graph.parent)
+	return attributesForData(n.effectiveData(), KeyForNode, n.graph.parent)
+}
+
+// GetAttributesWithSource returns data attributes map associated with Node (see GetAttributes) and the set of
+// attribute names whose values were filled from the defaults of their keys rather than from explicit data.
+func (n *Node) GetAttributesWithSource() (map[string]interface{}, map[string]bool, error) {
+	return attributesWithSourceForData(n.effectiveData(), KeyForNode, n.graph.parent)
+}
+
+// effectiveData returns the data of this node including the data inherited from the parent graph if
+// InheritGraphAttributes flag of GraphML is set
+func (n *Node) effectiveData() []*Data {
+	gml := n.graph.parent
+	if !gml.InheritGraphAttributes {
+		return n.Data
+	}
+	data := n.Data
+	for _, d := range n.graph.Data {
+		if key, ok := gml.keysById[d.Key]; !ok || key.Target != KeyForAll {
+			continue
+		}
+		if !hasDataWithKey(n.Data, d.Key) {
+			data = append(data[:len(data):len(data)], d)
+		}
+	}
+	return data
+}
+
+// GetAttribute returns the value of the attribute with given name associated with GraphML and the flag to indicate
+// whether it is found. The default value of the key is returned if GraphML has no data for the attribute.
+func (gml *GraphML) GetAttribute(name string) (interface{}, bool, error) {
+	return gml.attributeForData(gml.Data, KeyForGraphML, name)
+}
+
+// GetAttribute returns the value of the attribute with given name associated with Graph and the flag to indicate
+// whether it is found. The default value of the key is returned if graph has no data for the attribute.
+func (gr *Graph) GetAttribute(name string) (interface{}, bool, error) {
+	return gr.parent.attributeForData(gr.Data, KeyForGraph, name)
+}
+
+// GetAttribute returns the value of the attribute with given name associated with Node and the flag to indicate
+// whether it is found. The default value of the key is returned if node has no data for the attribute. The attributes
+// of parent graph are inherited in the same way as by GetAttributes.
+func (n *Node) GetAttribute(name string) (interface{}, bool, error) {
+	return n.graph.parent.attributeForData(n.effectiveData(), KeyForNode, name)
+}
+
+// GetAttribute returns the value of the attribute with given name associated with Edge and the flag to indicate
+// whether it is found. The default value of the key is returned if edge has no data for the attribute.
+func (e *Edge) GetAttribute(name string) (interface{}, bool, error) {
+	return e.graph.parent.attributeForData(e.Data, KeyForEdge, name)
+}
+
+// RawAttribute returns the literal value of data of GraphML for attribute with given name and the flag to indicate
+// whether such data is present. The default value of the key is never substituted.
+func (gml *GraphML) RawAttribute(name string) (value string, present bool) {
+	return gml.rawAttribute(gml.Data, name)
+}
+
+// RawAttribute returns the literal value of data of Graph for attribute with given name and the flag to indicate
+// whether such data is present. The default value of the key is never substituted.
+func (gr *Graph) RawAttribute(name string) (value string, present bool) {
+	return gr.parent.rawAttribute(gr.Data, name)
+}
+
+// RawAttribute returns the literal value of data of Node for attribute with given name and the flag to indicate
+// whether such data is present. The default value of the key is never substituted and attributes of parent graph
+// are never inherited.
+func (n *Node) RawAttribute(name string) (value string, present bool) {
+	return n.graph.parent.rawAttribute(n.Data, name)
+}
+
+// RawAttribute returns the literal value of data of Edge for attribute with given name and the flag to indicate
+// whether such data is present. The default value of the key is never substituted.
+func (e *Edge) RawAttribute(name string) (value string, present bool) {
+	return e.graph.parent.rawAttribute(e.Data, name)
+}
+
+// rawAttribute returns the literal value of data for attribute with given name from the specified data array
+func (gml *GraphML) rawAttribute(data []*Data, name string) (string, bool) {
+	for _, d := range data {
+		if key, ok := gml.keysById[d.Key]; ok && key.attributeName() == name {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+// attributeForData returns the value of the attribute with given name from the specified data array in the same way
+// as it would be included into the attributes map (see attributesForData)
+func (gml *GraphML) attributeForData(data []*Data, target KeyForElement, name string) (interface{}, bool, error) {
+	for _, d := range data {
+		key, ok := gml.keysById[d.Key]
+		if !ok || key.attributeName() != name {
+			continue
+		}
+		_, value, err := gml.ResolveData(d)
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	}
+	// use default value
+	for _, k := range keysForElement(gml.Keys, target) {
+		if k.attributeName() != name || (k.DefaultValue == "" && !k.emptyValueAllowed()) {
+			continue
+		}
+		value, err := gml.parseKeyValue(k.DefaultValue, k)
+		if err != nil {
+			return nil, false, errors.New("could not parse default value for key id: " + k.ID)
+		}
+		return value, true, nil
+	}
+	return nil, false, nil
+}
+
+// hasDataWithKey checks if given data list has data with given key ID
+func hasDataWithKey(data []*Data, keyId string) bool {
+	for _, d := range data {
+		if d.Key == keyId {
+			return true
+		}
+	}
+	return false
 }
 
 // GetAttributes returns data attributes map associated with Edge
@@ -649,54 +2004,264 @@ func (e *Edge) GetAttributes() (map[string]interface{}, error) {
 	return attributesForData(e.Data, KeyForEdge, e.graph.parent)
 }
 
+// IsAttributeDefaulted checks if the value of attribute with given name is filled from the default value of its key,
+// i.e., the GraphML has no explicit data for this attribute.
+func (gml *GraphML) IsAttributeDefaulted(name string) bool {
+	return gml.isAttributeDefaulted(gml.Data, KeyForGraphML, name)
+}
+
+// IsAttributeDefaulted checks if the value of attribute with given name is filled from the default value of its key,
+// i.e., the graph has no explicit data for this attribute.
+func (gr *Graph) IsAttributeDefaulted(name string) bool {
+	return gr.parent.isAttributeDefaulted(gr.Data, KeyForGraph, name)
+}
+
+// IsAttributeDefaulted checks if the value of attribute with given name is filled from the default value of its key,
+// i.e., the node has no explicit data for this attribute.
+func (n *Node) IsAttributeDefaulted(name string) bool {
+	return n.graph.parent.isAttributeDefaulted(n.Data, KeyForNode, name)
+}
+
+// IsAttributeDefaulted checks if the value of attribute with given name is filled from the default value of its key,
+// i.e., the edge has no explicit data for this attribute.
+func (e *Edge) IsAttributeDefaulted(name string) bool {
+	return e.graph.parent.isAttributeDefaulted(e.Data, KeyForEdge, name)
+}
+
+// isAttributeDefaulted checks if the value of attribute with given name is filled from the default value of its key
+// when attributes map built for specified data array (see attributesForData)
+func (gml *GraphML) isAttributeDefaulted(data []*Data, target KeyForElement, name string) bool {
+	key := gml.GetKey(name, target)
+	if key == nil || (key.DefaultValue == "" && !key.emptyValueAllowed()) {
+		return false
+	}
+	return !hasDataWithKey(data, key.ID)
+}
+
+// ResolveData returns the key of provided data and its typed value. If data has no value then the default value of
+// the key is used. Returns error if key not found or value can not be parsed.
+func (gml *GraphML) ResolveData(d *Data) (*Key, interface{}, error) {
+	key, ok := gml.keysById[d.Key]
+	if !ok {
+		return nil, nil, errors.New(fmt.Sprintf("failed to find attribute name/type by id: %s", d.Key))
+	}
+	// use data value or default value
+	dataValue := d.Value
+	if dataValue == "" && !key.emptyValueAllowed() {
+		if key.DefaultValue != "" {
+			dataValue = key.DefaultValue
+		} else {
+			return nil, nil, errors.New(fmt.Sprintf("data has no value and key id: %s has no default value", d.Key))
+		}
+	}
+
+	if raw, ok := d.complexValue(); ok && gml.LenientParsing {
+		return key, raw, nil
+	}
+	if value, ok := d.cachedValue(dataValue, key.KeyType); ok && gml.deserializers[key.KeyType] == nil {
+		return key, value, nil
+	}
+	value, err := gml.parseKeyValue(dataValue, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
+
 // builds attributes map for specified data array
 func attributesForData(data []*Data, target KeyForElement, gml *GraphML) (map[string]interface{}, error) {
+	attr, _, err := attributesWithSourceForData(data, target, gml)
+	return attr, err
+}
+
+// attributesWithSourceForData returns attributes for data collection with the set of attribute names whose values
+// were filled from the defaults of their keys
+func attributesWithSourceForData(data []*Data, target KeyForElement, gml *GraphML) (map[string]interface{}, map[string]bool, error) {
 	attr := make(map[string]interface{})
+	defaulted := make(map[string]bool)
 	for _, d := range data {
-		key, ok := gml.keysById[d.Key]
-		if !ok {
-			return nil, errors.New(fmt.Sprintf("failed to find attribute name/type by id: %s", d.Key))
-		}
-		// use data value or default value
-		dataValue := d.Value
-		if dataValue == "" && key.KeyType != StringType {
-			if key.DefaultValue != "" {
-				dataValue = key.DefaultValue
-			} else {
-				return nil, errors.New(fmt.Sprintf("data has no value and key id: %s has no default value", d.Key))
+		if gml.skipUnknownKey(d) {
+			continue
+		}
+		if key, value, err := gml.ResolveData(d); err != nil {
+			return nil, nil, err
+		} else {
+			attr[key.attributeName()] = value
+		}
+	}
+	// fill defaults for undefined keys
+	for _, k := range keysForElement(gml.Keys, target) {
+		if k.DefaultValue == "" && !k.emptyValueAllowed() {
+			continue
+		}
+		if _, ok := attr[k.attributeName()]; !ok {
+			val, err := gml.parseKeyValue(k.DefaultValue, k)
+			if err != nil {
+				return nil, nil, errors.New("could not parse default value for key id: " + k.ID)
 			}
+			attr[k.attributeName()] = val
+			defaulted[k.attributeName()] = true
 		}
+	}
+	return attr, defaulted, nil
+}
 
-		if value, err := valueByType(dataValue, key.KeyType, gml.keyTypeDefault); err != nil {
+// skipUnknownKey checks whether provided data refers to the key not declared and should be skipped
+// (see SkipUnknownKeys)
+func (gml *GraphML) skipUnknownKey(d *Data) bool {
+	if !gml.SkipUnknownKeys {
+		return false
+	}
+	_, ok := gml.keysById[d.Key]
+	return !ok
+}
+
+// AttributeView The attribute of element with its key and resolved value
+type AttributeView struct {
+	// The key of attribute
+	Key *Key
+	// The typed value of attribute
+	Value interface{}
+	// The flag to indicate whether the value is the default value of the key, i.e., element has no data for it
+	Defaulted bool
+}
+
+// AttributesWithKeys returns attributes of GraphML with their keys in the order of keys registration
+func (gml *GraphML) AttributesWithKeys() ([]AttributeView, error) {
+	return attributeViewsForData(gml.Data, KeyForGraphML, gml)
+}
+
+// AttributesWithKeys returns attributes of Graph with their keys in the order of keys registration
+func (gr *Graph) AttributesWithKeys() ([]AttributeView, error) {
+	return attributeViewsForData(gr.Data, KeyForGraph, gr.parent)
+}
+
+// AttributesWithKeys returns attributes of Node with their keys in the order of keys registration. The attributes
+// of parent graph are inherited in the same way as by GetAttributes.
+func (n *Node) AttributesWithKeys() ([]AttributeView, error) {
+	return attributeViewsForData(n.effectiveData(), KeyForNode, n.graph.parent)
+}
+
+// AttributesWithKeys returns attributes of Edge with their keys in the order of keys registration
+func (e *Edge) AttributesWithKeys() ([]AttributeView, error) {
+	return attributeViewsForData(e.Data, KeyForEdge, e.graph.parent)
+}
+
+// attributeViewsForData builds the list of attributes with their keys from the specified data array in the same way
+// as attributes map is built by attributesForData
+func attributeViewsForData(data []*Data, target KeyForElement, gml *GraphML) ([]AttributeView, error) {
+	views := make(map[string]AttributeView)
+	for _, d := range data {
+		if gml.skipUnknownKey(d) {
+			continue
+		}
+		key, value, err := gml.ResolveData(d)
+		if err != nil {
 			return nil, err
-		} else {
-			attr[key.Name] = value
 		}
+		views[key.attributeName()] = AttributeView{Key: key, Value: value}
 	}
 	// fill defaults for undefined keys
 	for _, k := range keysForElement(gml.Keys, target) {
-		if k.DefaultValue == "" && k.KeyType != StringType {
+		if k.DefaultValue == "" && !k.emptyValueAllowed() {
 			continue
 		}
-		if _, ok := attr[k.Name]; !ok {
-			val, err := valueByType(k.DefaultValue, k.KeyType, gml.keyTypeDefault)
+		if _, ok := views[k.attributeName()]; !ok {
+			val, err := gml.parseKeyValue(k.DefaultValue, k)
 			if err != nil {
 				return nil, errors.New("could not parse default value for key id: " + k.ID)
 			}
-			attr[k.Name] = val
+			views[k.attributeName()] = AttributeView{Key: k, Value: val, Defaulted: true}
+		}
+	}
+	res := make([]AttributeView, 0, len(views))
+	for _, k := range gml.Keys {
+		if v, ok := views[k.attributeName()]; ok && v.Key == k {
+			res = append(res, v)
 		}
 	}
-	return attr, nil
+	return res, nil
 }
 
 // appends given key
 func (gml *GraphML) addKey(key *Key) {
 	gml.Keys = append(gml.Keys, key)
-	keyIdentifier := keyIdentifier(key.Name, key.Target)
-	gml.keysByIdentifier[keyIdentifier] = key
+	for _, target := range key.targetList() {
+		gml.keysByIdentifier[keyIdentifier(key.Name, target)] = key
+	}
 	gml.keysById[key.ID] = key
 }
 
+// targetList returns the list of elements this key is for. The list holds multiple elements only for the keys
+// decoded from non-standard multi-target declarations, e.g., for="node edge".
+func (k *Key) targetList() []KeyForElement {
+	if k.targets != nil {
+		return k.targets
+	}
+	return []KeyForElement{k.Target}
+}
+
+// attributeName returns the name of attribute holding values of this key. The ID of key is used for the keys without
+// name, e.g., the keys of yEd graphics read with LenientParsing.
+func (k *Key) attributeName() string {
+	if k.Name == "" {
+		return k.ID
+	}
+	return k.Name
+}
+
+// emptyValueAllowed checks if the empty value is valid value of this key, i.e., the key holds plain strings
+func (k *Key) emptyValueAllowed() bool {
+	return k.KeyType == StringType && !k.formatted()
+}
+
+// unsigned checks if this key holds unsigned 64-bit integer values, i.e., long key with KeyFormatUint64 format
+func (k *Key) unsigned() bool {
+	return k.KeyType == LongType && k.Format == KeyFormatUint64
+}
+
+// formatted checks if this key holds values of one of the special formats supported, e.g., KeyFormatTime
+func (k *Key) formatted() bool {
+	switch k.Format {
+	case KeyFormatTime, KeyFormatBigInt, KeyFormatBigFloat, KeyFormatJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// appliesToAny checks if this key is applicable to any of the given target elements
+func (k *Key) appliesToAny(targets map[KeyForElement]bool) bool {
+	for target := range targets {
+		if k.appliesTo(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo checks if this key is applicable to the given target element
+func (k *Key) appliesTo(target KeyForElement) bool {
+	for _, t := range k.targetList() {
+		if t == target || t == KeyForAll {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKeyTargets splits the value of key's "for" attribute into the list of targets separated by spaces or commas
+func parseKeyTargets(value string) []KeyForElement {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	res := make([]KeyForElement, len(fields))
+	for i, f := range fields {
+		res[i] = KeyForElement(f)
+	}
+	return res
+}
+
 // Creates data-functions from given attributes and appends definitions of created functions to the provided data list.
 func (gml *GraphML) createDataAttributes(attributes map[string]interface{}, target KeyForElement) (data []*Data, err error) {
 	// make sure that attributes are sorted in predictable order
@@ -725,41 +2290,211 @@ func (gml *GraphML) createDataAttributes(attributes map[string]interface{}, targ
 // If there is no key with this name and target, a new one is registered.
 func (gml *GraphML) createDataAttribute(value interface{}, key string, target KeyForElement) (data *Data, err error) {
 	keyFunc := gml.GetKey(key, target)
-	if keyFunc == nil {
+	if format := valueFormat(value); format != "" && keyFunc == nil {
+		// register new Key for values of special format
+		if keyFunc, err = gml.registerFormattedKey(target, key, "", format, nil); err != nil {
+			return nil, err
+		}
+	} else if keyFunc == nil {
 		// register new Key
 		if keyFunc, err = gml.RegisterKey(target, key, "", reflect.TypeOf(value).Kind(), nil); err != nil {
 			// failed
 			return nil, err
 		}
 	}
-	return createDataWithKey(value, keyFunc)
+	if keyFunc.unsigned() {
+		// the unsigned values are converted when stored (see keyStringValue)
+		return gml.createDataWithKey(value, keyFunc)
+	}
+	if value, err = gml.coerceValue(value, keyFunc.KeyType); err != nil {
+		return nil, err
+	}
+	return gml.createDataWithKey(value, keyFunc)
+}
+
+// coerceValue converts provided value to the type compatible with given key type if appropriate, thus the type of
+// registered key wins over the type of value. The float value is converted to int64 when int/long key type expected
+// and value has no fractional part. The integer value is converted to int or int64 when int or long key type expected,
+// and to float32 or float64 when float or double key type expected if it can be represented exactly. No conversions
+// are applied if StrictNumericTypes set. The values are never rounded or truncated - the error is returned instead.
+func (gml *GraphML) coerceValue(value interface{}, keyType DataType) (interface{}, error) {
+	if gml.StrictNumericTypes || value == nil {
+		return value, nil
+	}
+	switch keyType {
+	case IntType, LongType:
+		if iVal, ok := integerValue(value); ok {
+			if keyType == IntType {
+				return int(iVal), nil
+			}
+			return iVal, nil
+		} else if unsignedLongKind(reflect.ValueOf(value).Kind()) {
+			// the unsigned values above math.MaxInt64 (see KeyFormatUint64)
+			return nil, errors.New(fmt.Sprintf("integer value is out of range of %s: %v", keyType, value))
+		}
+	case FloatType, DoubleType:
+		if iVal, ok := integerValue(value); ok {
+			if keyType == FloatType && int64(float32(iVal)) == iVal {
+				return float32(iVal), nil
+			} else if keyType == DoubleType && int64(float64(iVal)) == iVal {
+				return float64(iVal), nil
+			}
+			return nil, errors.New(fmt.Sprintf("integer value can not be stored as %s exactly: %v", keyType, value))
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+	var fVal float64
+	switch v := value.(type) {
+	case float32:
+		fVal = float64(v)
+	case float64:
+		fVal = v
+	default:
+		return value, nil
+	}
+	if fVal != math.Trunc(fVal) || math.IsInf(fVal, 0) {
+		return nil, errors.New(fmt.Sprintf("float value with fractional part can not be stored as %s: %v", keyType, value))
+	}
+	if fVal < math.MinInt64 || fVal >= math.MaxInt64 {
+		return nil, errors.New(fmt.Sprintf("float value is out of range of %s: %v", keyType, value))
+	}
+	return int64(fVal), nil
+}
+
+// integerValue returns the value of signed integer or unsigned integer fitting int64 as int64 and flag to indicate
+// whether conversion succeeded. The unsigned values above math.MaxInt64 are not converted.
+func integerValue(value interface{}) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// unsignedValue returns the value of unsigned integer or non-negative signed integer as uint64 and flag to indicate
+// whether conversion succeeded
+func unsignedValue(value interface{}) (uint64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() >= 0 {
+			return uint64(v.Int()), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	}
+	return 0, false
+}
+
+// unsignedLongKind checks if given kind is of unsigned integer type which values may exceed math.MaxInt64, thus
+// requiring the key of KeyFormatUint64 format
+func unsignedLongKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
 }
 
 // Creates data object with specified name, value and for provided Key
-func createDataWithKey(value interface{}, key *Key) (data *Data, err error) {
+func (gml *GraphML) createDataWithKey(value interface{}, key *Key) (data *Data, err error) {
 	data = &Data{
 		Key: key.ID,
 	}
 	// add value
-	if value != NotAValue {
-		if data.Value, err = stringValueIfSupported(value, key.KeyType); err == nil {
-			return data, nil
+	if value != NotAValue && key.Format == KeyFormatJSON {
+		var encoded []byte
+		if encoded, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+		data.Value = string(encoded)
+	} else if value != NotAValue {
+		if key.formatted() && valueFormat(value) != key.Format {
+			return nil, errors.New(fmt.Sprintf("value has wrong data type when %s expected: %T", key.Format, value))
+		}
+		if data.Value, err = gml.keyStringValue(value, key); err != nil {
+			return nil, err
+		}
+		if !key.unsigned() {
+			data.cacheValue(value, key.KeyType)
 		}
 	} else if key.Target == KeyForAll && len(key.DefaultValue) > 0 {
 		// use default value
 		data.Value = key.DefaultValue
 	} else {
 		// raise error
-		return nil, errors.New(fmt.Sprintf("empty attribute without default value: %s", key.Name))
+		return nil, fmt.Errorf("%w: %s", ErrEmptyAttributeNoDefault, key.Name)
 	}
 	return data, nil
 }
 
+// cacheValue stores the typed value of this data if its Go type is the one returned for the given key type when parsed
+// from the string, thus avoiding parsing of the string value when attributes requested.
+func (d *Data) cacheValue(value interface{}, keyType DataType) {
+	var ok bool
+	switch keyType {
+	case BooleanType:
+		_, ok = value.(bool)
+	case IntType:
+		_, ok = value.(int)
+	case LongType:
+		_, ok = value.(int64)
+	case FloatType:
+		_, ok = value.(float32)
+	case DoubleType:
+		_, ok = value.(float64)
+	case StringType:
+		_, ok = value.(string)
+	}
+	if ok {
+		d.typed, d.typedFrom, d.typedType = value, d.Value, keyType
+	}
+}
+
+// complexValue returns the raw XML of the complex value of this data if it is still valid, i.e., the value of data
+// was not changed after decoding
+func (d *Data) complexValue() (string, bool) {
+	if d.complexXML == "" || d.Value != d.complexFrom {
+		return "", false
+	}
+	return d.complexXML, true
+}
+
+// cachedValue returns the cached typed value of this data if it is still valid for given string value and key type
+func (d *Data) cachedValue(value string, keyType DataType) (interface{}, bool) {
+	if d.typed == nil || d.typedFrom != value || d.typedType != keyType {
+		return nil, false
+	}
+	return d.typed, true
+}
+
 // returns standard edge identifier based on provided iDs of connected nodes
 func edgeIdentifier(source, target string) string {
 	return fmt.Sprintf("%s<->%s", source, target)
 }
 
+// portEdgeIdentifier builds the identifier of the edge connecting given ports of the nodes. The identifier of the edge
+// not attached to any port is the same as returned by edgeIdentifier.
+func portEdgeIdentifier(source, sourcePort, target, targetPort string) string {
+	if sourcePort == "" && targetPort == "" {
+		return edgeIdentifier(source, target)
+	}
+	return fmt.Sprintf("%s:%s<->%s:%s", source, sourcePort, target, targetPort)
+}
+
+// identifier returns the identifier of this edge in the edges map
+func (e *Edge) identifier() string {
+	return portEdgeIdentifier(e.Source, e.SourcePortName, e.Target, e.TargetPortName)
+}
+
 // returns standard key identifier based on provided name and target
 func keyIdentifier(name string, target KeyForElement) string {
 	return fmt.Sprintf("%s_for_%s", name, target)
@@ -773,7 +2508,7 @@ func typeNameForKind(kind reflect.Kind) (DataType, error) {
 		keyType = BooleanType
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
 		keyType = IntType
-	case reflect.Int64, reflect.Uint32:
+	case reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64, reflect.Uintptr:
 		keyType = LongType
 	case reflect.Float32:
 		keyType = FloatType
@@ -787,9 +2522,135 @@ func typeNameForKind(kind reflect.Kind) (DataType, error) {
 	return keyType, nil
 }
 
-// Converts provided value to string if it's supported by this keyType
+// SetSerializer sets the function to be used for conversion of attribute values of given key type into their string
+// representation stored in the data elements and default values of keys. Setting nil function restores the default
+// conversion.
+func (gml *GraphML) SetSerializer(keyType DataType, fn func(interface{}) (string, error)) {
+	if fn == nil {
+		delete(gml.serializers, keyType)
+		return
+	}
+	if gml.serializers == nil {
+		gml.serializers = make(map[DataType]func(interface{}) (string, error))
+	}
+	gml.serializers[keyType] = fn
+}
+
+// SetDeserializer sets the function to be used for parsing of attribute values of given key type from their string
+// representation stored in the data elements and default values of keys. Setting nil function restores the default
+// parsing.
+func (gml *GraphML) SetDeserializer(keyType DataType, fn func(string) (interface{}, error)) {
+	if fn == nil {
+		delete(gml.deserializers, keyType)
+		return
+	}
+	if gml.deserializers == nil {
+		gml.deserializers = make(map[DataType]func(string) (interface{}, error))
+	}
+	gml.deserializers[keyType] = fn
+}
+
+// stringValue converts provided value into string using serializer registered for given key type or the default
+// conversion (see stringValueIfSupported)
+func (gml *GraphML) stringValue(value interface{}, keyType DataType) (string, error) {
+	if fn, ok := gml.serializers[keyType]; ok {
+		return fn(value)
+	}
+	return stringValueIfSupported(value, keyType)
+}
+
+// keyStringValue converts provided value into string value of data of given key. The values of key with
+// KeyFormatUint64 format must be non-negative integers, which are converted into decimal strings unless serializer of
+// long values is registered.
+func (gml *GraphML) keyStringValue(value interface{}, key *Key) (string, error) {
+	if !key.unsigned() {
+		return gml.stringValue(value, key.KeyType)
+	}
+	uVal, ok := unsignedValue(value)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("value has wrong data type when %s expected: %v", KeyFormatUint64, value))
+	}
+	if fn, ok := gml.serializers[key.KeyType]; ok {
+		return fn(uVal)
+	}
+	return strconv.FormatUint(uVal, 10), nil
+}
+
+// parseValue parses provided string value using deserializer registered for given key type or the default
+// parsing (see valueByType)
+func (gml *GraphML) parseValue(val string, keyType DataType) (interface{}, error) {
+	if fn, ok := gml.deserializers[keyType]; ok {
+		return fn(val)
+	}
+	return valueByType(val, keyType, gml.keyTypeDefault)
+}
+
+// parseKeyValue parses provided string value of given key. The values of keys with KeyFormatTime, KeyFormatBigInt,
+// KeyFormatBigFloat and KeyFormatUint64 formats are parsed into time.Time, *big.Int, *big.Float and uint64
+// respectively unless deserializer of their key type is registered, otherwise the value is parsed according to the
+// type of key (see parseValue). If LenientParsing flag is set then the value which can not be parsed is returned as is.
+func (gml *GraphML) parseKeyValue(val string, key *Key) (value interface{}, err error) {
+	if _, ok := gml.deserializers[key.KeyType]; !ok && (key.formatted() || key.unsigned()) {
+		value, err = valueByFormat(val, key.Format)
+	} else {
+		value, err = gml.parseValue(val, key.KeyType)
+	}
+	if err != nil && gml.LenientParsing {
+		return val, nil
+	}
+	return value, err
+}
+
+// valueByFormat parses provided string value of key with given format
+func valueByFormat(val string, format string) (interface{}, error) {
+	val = strings.TrimSpace(val)
+	switch format {
+	case KeyFormatTime:
+		return time.Parse(time.RFC3339Nano, val)
+	case KeyFormatBigInt:
+		if value, ok := new(big.Int).SetString(val, 10); ok {
+			return value, nil
+		}
+		return nil, errors.New(fmt.Sprintf("failed to parse big integer: %s", val))
+	case KeyFormatBigFloat:
+		// the precision enough to hold all decimal digits of value, but not less than the one of float64
+		prec := uint(math.Ceil(float64(len(val)) * math.Log2(10)))
+		if prec < 64 {
+			prec = 64
+		}
+		if value, ok := new(big.Float).SetPrec(prec).SetString(val); ok {
+			return value, nil
+		}
+		return nil, errors.New(fmt.Sprintf("failed to parse big float: %s", val))
+	case KeyFormatJSON:
+		var value interface{}
+		if err := json.Unmarshal([]byte(val), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case KeyFormatUint64:
+		return strconv.ParseUint(val, 10, 64)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported key format: %s", format))
+	}
+}
+
+// Converts provided value to string if it's supported by this keyType. The time.Time values are supported by string
+// key type and converted into RFC3339 strings, the *big.Int and *big.Float values are converted into decimal strings.
+// The NaN and infinite float values are converted into "NaN", "INF" and "-INF" as defined by XML Schema, which are
+// parsed back by valueByType.
 func stringValueIfSupported(value interface{}, keyType DataType) (string, error) {
 	res := "unsupported"
+	if keyType == StringType {
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		case *big.Int:
+			return v.String(), nil
+		case *big.Float:
+			return v.Text('g', -1), nil
+		}
+	}
 	// check that key and value types compatible
 	switch keyType {
 	case BooleanType:
@@ -803,6 +2664,10 @@ func stringValueIfSupported(value interface{}, keyType DataType) (string, error)
 			return res, errors.New(
 				fmt.Sprintf("default value has wrong data type when int/long expected: %s", defTypeName))
 		}
+		// the unsigned values above math.MaxInt64 can be stored only by keys of KeyFormatUint64 format
+		if v := reflect.ValueOf(value); unsignedLongKind(v.Kind()) && v.Uint() > math.MaxInt64 {
+			return res, errors.New(fmt.Sprintf("unsigned value is out of range of %s: %v", keyType, value))
+		}
 	case FloatType, DoubleType:
 		if defTypeName, err := typeNameForKind(reflect.TypeOf(value).Kind()); err != nil {
 			return res, err
@@ -810,6 +2675,9 @@ func stringValueIfSupported(value interface{}, keyType DataType) (string, error)
 			return res, errors.New(
 				fmt.Sprintf("default value has wrong data type when float/double expected: %s", defTypeName))
 		}
+		if token, ok := specialFloatString(reflect.ValueOf(value).Float()); ok {
+			return token, nil
+		}
 	case StringType:
 		if defTypeName, err := typeNameForKind(reflect.TypeOf(value).Kind()); err != nil {
 			return res, err
@@ -821,13 +2689,28 @@ func stringValueIfSupported(value interface{}, keyType DataType) (string, error)
 	return fmt.Sprint(value), nil
 }
 
+// specialFloatString returns the XML Schema token of NaN or infinite float value, i.e., "NaN", "INF" or "-INF"
+func specialFloatString(value float64) (string, bool) {
+	switch {
+	case math.IsNaN(value):
+		return "NaN", true
+	case math.IsInf(value, 1):
+		return "INF", true
+	case math.IsInf(value, -1):
+		return "-INF", true
+	default:
+		return "", false
+	}
+}
+
 // Converts provided string value to the specified data type
 func valueByType(val string, keyType DataType, keyTypeDefault DataType) (interface{}, error) {
 	switch keyType {
 	case BooleanType:
-		return strconv.ParseBool(val)
+		return strconv.ParseBool(strings.TrimSpace(val))
 	case IntType, LongType:
-		if iVal, err := strconv.ParseInt(val, 10, 64); err != nil {
+		// the leading plus sign is accepted by the parser, but the surrounding whitespace is not
+		if iVal, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err != nil {
 			return nil, err
 		} else if keyType == IntType {
 			return int(iVal), nil
@@ -835,7 +2718,7 @@ func valueByType(val string, keyType DataType, keyTypeDefault DataType) (interfa
 			return iVal, nil
 		}
 	case FloatType, DoubleType:
-		if fVal, err := strconv.ParseFloat(val, 64); err != nil {
+		if fVal, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err != nil {
 			return nil, err
 		} else if keyType == FloatType {
 			return float32(fVal), nil
@@ -856,7 +2739,7 @@ func valueByType(val string, keyType DataType, keyTypeDefault DataType) (interfa
 // keysForElement returns all the keys from allKeys that apply to a certain element
 func keysForElement(allKeys []*Key, target KeyForElement) (keys []*Key) {
 	for _, k := range allKeys {
-		if k.Target == target || k.Target == KeyForAll {
+		if k.appliesTo(target) {
 			keys = append(keys, k)
 		}
 	}
@@ -2,13 +2,20 @@ package graphml
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math"
+	"math/big"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewGraphML(t *testing.T) {
@@ -18,6 +25,24 @@ func TestNewGraphML(t *testing.T) {
 	assert.Equal(t, description, gml.Description)
 }
 
+func TestGraphML_SetNamespace(t *testing.T) {
+	gml := NewGraphML("")
+	gml.SetNamespace("http://example.com/xmlns", "", "http://example.com/xmlns http://example.com/1.1/graphml.xsd")
+	assert.Equal(t, DefaultXsiNamespace, gml.XmlnsXsi)
+
+	outBuf := &bytes.Buffer{}
+	err := gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Equal(t, `<graphml xmlns="http://example.com/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" `+
+		`xsi:schemaLocation="http://example.com/xmlns http://example.com/1.1/graphml.xsd"></graphml>`, outBuf.String())
+
+	// restore defaults
+	gml.SetNamespace("", "", "")
+	assert.Equal(t, DefaultNamespace, gml.XmlNS)
+	assert.Equal(t, DefaultXsiNamespace, gml.XmlnsXsi)
+	assert.Equal(t, DefaultSchemaLocation, gml.XsiSchemaLocation)
+}
+
 func TestNewGraphMLWithAttributes(t *testing.T) {
 	description := "test"
 
@@ -269,7 +294,7 @@ func TestGraphML_Encode(t *testing.T) {
 	err = gml.Encode(outBuf, false)
 
 	// check results
-	const resString = "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\"><desc>TestGraphML_Encode</desc><key id=\"d0\" for=\"all\" attr.name=\"attr_double\" attr.type=\"double\"><desc>common double data-function</desc><default>10.2</default></key><key id=\"d1\" for=\"graph\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d2\" for=\"graph\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d3\" for=\"graph\" attr.name=\"attr_string\" attr.type=\"string\"></key><key id=\"d4\" for=\"node\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d5\" for=\"node\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d6\" for=\"node\" attr.name=\"attr_string\" attr.type=\"string\"></key><key id=\"d7\" for=\"edge\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d8\" for=\"edge\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d9\" for=\"edge\" attr.name=\"attr_string\" attr.type=\"string\"></key><graph id=\"g0\" edgedefault=\"directed\"><desc>test graph</desc><node id=\"n0\"><desc>test node #1</desc><data key=\"d4\">false</data><data key=\"d0\">10.2</data><data key=\"d5\">120</data><data key=\"d6\">string data</data></node><node id=\"n1\"><desc>test node #2</desc><data key=\"d4\">false</data><data key=\"d0\">10.2</data><data key=\"d5\">120</data><data key=\"d6\">string data</data></node><edge id=\"e0\" source=\"n0\" target=\"n1\"><desc>test edge</desc><data key=\"d7\">false</data><data key=\"d0\">10.2</data><data key=\"d8\">120</data><data key=\"d9\">string data</data></edge><data key=\"d1\">false</data><data key=\"d0\">10.2</data><data key=\"d2\">120</data><data key=\"d3\">string data</data></graph></graphml>"
+	const resString = "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\"><desc>TestGraphML_Encode</desc><key id=\"d0\" for=\"all\" attr.name=\"attr_double\" attr.type=\"double\"><desc>common double data-function</desc><default>10.2</default></key><key id=\"d1\" for=\"graph\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d2\" for=\"graph\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d3\" for=\"graph\" attr.name=\"attr_string\" attr.type=\"string\"></key><key id=\"d4\" for=\"node\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d5\" for=\"node\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d6\" for=\"node\" attr.name=\"attr_string\" attr.type=\"string\"></key><key id=\"d7\" for=\"edge\" attr.name=\"attr_bool\" attr.type=\"boolean\"></key><key id=\"d8\" for=\"edge\" attr.name=\"attr_integer\" attr.type=\"int\"></key><key id=\"d9\" for=\"edge\" attr.name=\"attr_string\" attr.type=\"string\"></key><graph id=\"g0\" edgedefault=\"directed\"><desc>test graph</desc><node id=\"n0\"><desc>test node #1</desc><data key=\"d0\">10.2</data><data key=\"d4\">false</data><data key=\"d5\">120</data><data key=\"d6\">string data</data></node><node id=\"n1\"><desc>test node #2</desc><data key=\"d0\">10.2</data><data key=\"d4\">false</data><data key=\"d5\">120</data><data key=\"d6\">string data</data></node><edge id=\"e0\" source=\"n0\" target=\"n1\"><desc>test edge</desc><data key=\"d0\">10.2</data><data key=\"d7\">false</data><data key=\"d8\">120</data><data key=\"d9\">string data</data></edge><data key=\"d0\">10.2</data><data key=\"d1\">false</data><data key=\"d2\">120</data><data key=\"d3\">string data</data></graph></graphml>"
 	assert.Equal(t, resString, outBuf.String())
 }
 
@@ -321,6 +346,7 @@ func TestGraphML_AddGraph(t *testing.T) {
 	// test error
 	graph, err = gml.AddGraph(description, EdgeDirectionDefault, nil)
 	assert.EqualError(t, err, "default edge direction must be provided")
+	assert.True(t, errors.Is(err, ErrNoEdgeDirection))
 	assert.Nil(t, graph)
 }
 
@@ -355,6 +381,39 @@ func TestGraph_GetEdge(t *testing.T) {
 	assert.Nil(t, edge, "edge is not expected")
 }
 
+func TestGraph_GetEdge_Undirected(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n3, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	edge, err := gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	directed, err := gr.AddEdge(n2, n3, nil, EdgeDirectionDirected, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, edge, gr.GetEdge(n1.ID, n2.ID))
+	assert.Equal(t, edge, gr.GetEdge(n2.ID, n1.ID))
+	assert.Equal(t, directed, gr.GetEdge(n2.ID, n3.ID))
+	assert.Nil(t, gr.GetEdge(n3.ID, n2.ID), "directed edge is found only in its direction")
+
+	// decoded
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	edge = decoded.Graphs[0].GetEdge(n2.ID, n1.ID)
+	require.NotNil(t, edge)
+	assert.Equal(t, n1.ID, edge.Source)
+	assert.Nil(t, decoded.Graphs[0].GetEdge(n3.ID, n2.ID))
+}
+
 func TestGraph_GetNode(t *testing.T) {
 	description := "test graph"
 	gml := NewGraphML("")
@@ -442,6 +501,141 @@ func TestGraphML_RegisterKeyDefaultValue(t *testing.T) {
 	assert.Nil(t, n, "node should not be added due to attribute error")
 	assert.EqualError(t, err, "empty attribute without default value: height",
 		"error must be raised for empty attribute and no default value")
+	assert.True(t, errors.Is(err, ErrEmptyAttributeNoDefault))
+}
+
+func TestGraphML_RegisterTimeKey(t *testing.T) {
+	gml := NewGraphML("")
+	created := time.Date(2020, time.March, 1, 12, 30, 15, 500, time.FixedZone("EET", 2*60*60))
+	key, err := gml.RegisterTimeKey(KeyForNode, "created", "", created)
+	require.NoError(t, err)
+	assert.Equal(t, StringType, key.KeyType)
+	assert.Equal(t, KeyFormatTime, key.Format)
+	assert.Equal(t, "2020-03-01T12:30:15.0000005+02:00", key.DefaultValue)
+
+	_, err = gml.RegisterTimeKey(KeyForNode, "modified", "", "yesterday")
+	assert.EqualError(t, err, "default value has wrong data type when time expected: string")
+
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	modified := time.Date(2021, time.May, 2, 8, 0, 0, 0, time.UTC)
+	_, err = gr.AddNode(map[string]interface{}{"modified": modified}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"created": "yesterday"}, "")
+	assert.EqualError(t, err, "value has wrong data type when time expected: string")
+
+	// check that time values survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `attr.name="modified" attr.type="string" ggml:format="time"`)
+
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 2)
+	assert.True(t, created.Equal(attrs["created"].(time.Time)))
+	assert.True(t, modified.Equal(attrs["modified"].(time.Time)))
+}
+
+func TestGraphML_BigNumberAttributes(t *testing.T) {
+	gml := NewGraphML("")
+	bigInt, ok := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.True(t, ok)
+	bigFloat, ok := new(big.Float).SetPrec(200).SetString("3.14159265358979323846264338327950288419716939937510")
+	require.True(t, ok)
+	key, err := gml.RegisterBigIntKey(KeyForNode, "count", "", big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, KeyFormatBigInt, key.Format)
+	assert.Equal(t, "1", key.DefaultValue)
+	_, err = gml.RegisterBigFloatKey(KeyForNode, "ratio", "", 1.5)
+	assert.EqualError(t, err, "default value has wrong data type when bigfloat expected: float64")
+
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"count": bigInt, "weight": bigFloat}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"weight": 1.5}, "")
+	assert.EqualError(t, err, "value has wrong data type when bigfloat expected: float64")
+
+	// check that values survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `attr.name="weight" attr.type="string" ggml:format="bigfloat"`)
+	assert.Contains(t, outBuf.String(), `>-123456789012345678901234567890</data>`)
+
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 2)
+	assert.Equal(t, 0, bigInt.Cmp(attrs["count"].(*big.Int)))
+	assert.Equal(t, bigFloat.Text('g', -1), attrs["weight"].(*big.Float).Text('g', -1))
+	attrs, err = decoded.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": big.NewInt(1)}, attrs)
+
+	// wrong value
+	decoded.Graphs[0].Nodes[0].Data[0].Value = "1.5"
+	_, err = decoded.Graphs[0].Nodes[0].GetAttributes()
+	assert.EqualError(t, err, "failed to parse big integer: 1.5")
+}
+
+func TestGraphML_JSONAttributes(t *testing.T) {
+	gml := NewGraphML("")
+	key, err := gml.RegisterJSONKey(KeyForNode, "tags", "", []string{"none"})
+	require.NoError(t, err)
+	assert.Equal(t, KeyFormatJSON, key.Format)
+	assert.Equal(t, StringType, key.KeyType)
+	assert.Equal(t, `["none"]`, key.DefaultValue)
+	_, err = gml.RegisterJSONKey(KeyForNode, "props", "", nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterJSONKey(KeyForNode, "broken", "", func() {})
+	assert.Error(t, err)
+
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{
+		"tags":  []string{"a", "b"},
+		"props": map[string]int{"x": 1, "y": 2},
+	}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	// check that values survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `attr.name="tags" attr.type="string" ggml:format="json"`)
+	assert.Contains(t, outBuf.String(), `>{&#34;x&#34;:1,&#34;y&#34;:2}</data>`)
+
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, attrs["tags"])
+	assert.Equal(t, map[string]interface{}{"x": 1.0, "y": 2.0}, attrs["props"])
+	attrs, err = decoded.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"tags": []interface{}{"none"}}, attrs)
+
+	// the raw value is plain JSON string
+	raw, ok := decoded.Graphs[0].Nodes[0].RawAttribute("tags")
+	assert.True(t, ok)
+	assert.Equal(t, `["a","b"]`, raw)
+
+	// wrong value
+	decoded.Graphs[0].Nodes[0].Data[0].Value = "{"
+	_, err = decoded.Graphs[0].Nodes[0].GetAttributes()
+	assert.Error(t, err)
 }
 
 func TestGraphML_RegisterKeyForAll(t *testing.T) {
@@ -498,6 +692,7 @@ func TestGraphML_RegisterKey(t *testing.T) {
 	_, err = gml.RegisterKey(KeyForNode, keyName, keyDesc, reflect.TypeOf(keyDefault).Kind(), keyDefault+100)
 	assert.EqualError(t, err, fmt.Sprintf("key with given name already registered: %s", keyName),
 		"error should be raised when attempting to register Key with already existing standard identifier")
+	assert.True(t, errors.Is(err, ErrKeyAlreadyRegistered))
 
 	// register another key and check ID
 	keyName = "height"
@@ -507,6 +702,106 @@ func TestGraphML_RegisterKey(t *testing.T) {
 	assert.Equal(t, "d1", gml.Keys[1].ID)
 }
 
+func TestGraphML_RegisterKeyFromValue(t *testing.T) {
+	gml := NewGraphML("")
+	key, err := gml.RegisterKeyFromValue(KeyForNode, "weight", "node weight", 1.5)
+	require.NoError(t, err)
+	assert.Equal(t, DoubleType, key.KeyType)
+	assert.Equal(t, "1.5", key.DefaultValue)
+	assert.Equal(t, "node weight", key.Description)
+
+	key, err = gml.RegisterKeyFromValue(KeyForEdge, "count", "", int64(42))
+	require.NoError(t, err)
+	assert.Equal(t, LongType, key.KeyType)
+	assert.Equal(t, "42", key.DefaultValue)
+
+	key, err = gml.RegisterKeyFromValue(KeyForGraph, "created", "", time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, StringType, key.KeyType)
+	assert.Equal(t, KeyFormatTime, key.Format)
+	assert.Equal(t, "2020-03-01T00:00:00Z", key.DefaultValue)
+
+	_, err = gml.RegisterKeyFromValue(KeyForNode, "empty", "", nil)
+	assert.EqualError(t, err, "value must be provided to infer the type of key")
+	_, err = gml.RegisterKeyFromValue(KeyForNode, "weight", "", 2.5)
+	assert.True(t, errors.Is(err, ErrKeyAlreadyRegistered))
+
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	attrs, err := n.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.5}, attrs)
+}
+
+func TestGraphML_GetOrRegisterKey(t *testing.T) {
+	gml := NewGraphML("")
+	key, err := gml.GetOrRegisterKey(KeyForNode, "weight", "node weight", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	assert.Equal(t, DoubleType, key.KeyType)
+	assert.Equal(t, "1", key.DefaultValue)
+	assert.Len(t, gml.Keys, 1)
+
+	existing, err := gml.GetOrRegisterKey(KeyForNode, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	assert.Same(t, key, existing)
+	assert.Len(t, gml.Keys, 1)
+
+	_, err = gml.GetOrRegisterKey(KeyForNode, "weight", "", reflect.Int, nil)
+	assert.EqualError(t, err, "key: weight already registered with type: double, requested type: int")
+
+	existing, err = gml.GetOrRegisterKey(KeyForNode, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	assert.Same(t, key, existing)
+	_, err = gml.GetOrRegisterKey(KeyForNode, "weight", "", reflect.Float64, 2.5)
+	assert.EqualError(t, err, "key: weight already registered with default value: 1, requested default value: 2.5")
+
+	// the common key is found for any element
+	common, err := gml.GetOrRegisterKey(KeyForAll, "name", "", reflect.String, nil)
+	require.NoError(t, err)
+	key, err = gml.GetOrRegisterKey(KeyForEdge, "name", "", reflect.String, nil)
+	require.NoError(t, err)
+	assert.Same(t, common, key)
+
+	key, err = gml.GetOrRegisterKey(KeyForEdge, "weight", "", reflect.Int, nil)
+	require.NoError(t, err)
+	assert.Equal(t, IntType, key.KeyType)
+	assert.Len(t, gml.Keys, 3)
+}
+
+func TestGraphML_RegisterKeyWithID(t *testing.T) {
+	gml := NewGraphML("")
+	key, err := gml.RegisterKeyWithID("d1", KeyForNode, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	assert.Equal(t, "d1", key.ID)
+	assert.Equal(t, key, gml.GetKey("weight", KeyForNode))
+
+	_, err = gml.RegisterKeyWithID("d1", KeyForEdge, "weight", "", reflect.Float64, nil)
+	assert.EqualError(t, err, "key with ID: d1 already exists")
+	_, err = gml.RegisterKeyWithID("", KeyForEdge, "weight", "", reflect.Float64, nil)
+	assert.EqualError(t, err, "key ID must be provided")
+	_, err = gml.RegisterKeyWithID("w", KeyForNode, "weight", "", reflect.Float64, nil)
+	assert.True(t, errors.Is(err, ErrKeyAlreadyRegistered))
+
+	// automatically assigned IDs skip the taken one
+	key, err = gml.RegisterKey(KeyForNode, "name", "", reflect.String, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "d2", key.ID)
+	_, err = gml.RegisterKeyWithID("label", KeyForNode, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"label": "first"}, "")
+	require.NoError(t, err)
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), `<key id="label" for="node" attr.name="label" attr.type="string"></key>`)
+	assert.Contains(t, outBuf.String(), `<data key="label">first</data>`)
+}
+
 func TestGraph_AddNode(t *testing.T) {
 	description := "test graph"
 	gml := NewGraphML("")
@@ -696,7 +991,48 @@ func TestGraph_RemoveKeyByName(t *testing.T) {
 
 	// try removing not existing key
 	err = gml.RemoveKeyByName(KeyForAll, "not existing")
-	assert.Error(t, err, "key no found")
+	assert.EqualError(t, err, "key not found: not existing")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
+
+func TestGraphML_RemoveAttributeByName(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForAll, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	err = gml.SetAttribute("name", "root")
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, map[string]interface{}{"weight": 1.0})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"weight": 2.0, "color": "red"}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"weight": 3.0}, "")
+	require.NoError(t, err)
+	e, err := gr.AddEdge(n1, n2, map[string]interface{}{"weight": 4.0}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	// only the attribute of given element is removed
+	n1.RemoveAttributeByName("weight")
+	attrs, err := n1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, attrs)
+	_, ok, err := n2.GetAttribute("weight")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	gr.RemoveAttributeByName("weight")
+	assert.Empty(t, gr.Data)
+	e.RemoveAttributeByName("weight")
+	assert.Empty(t, e.Data)
+	gml.RemoveAttributeByName("name")
+	assert.Empty(t, gml.Data)
+
+	// unknown names are ignored
+	n2.RemoveAttributeByName("unknown")
+	assert.Len(t, n2.Data, 1)
+	// the key is resolved for target element
+	n2.RemoveAttributeByName("name")
+	assert.Len(t, n2.Data, 1)
+	assert.NotNil(t, gml.GetKey("weight", KeyForAll))
 }
 
 func TestGraph_SetAttribute(t *testing.T) {
@@ -792,6 +1128,15 @@ func TestGraph_SetAttribute(t *testing.T) {
 	err = e1.SetAttribute("invalid", make(chan bool))
 	require.Error(t, err)
 	require.Len(t, gml.Keys, 5)
+
+	// try setting value of incompatible type
+	err = n1.SetAttribute(attrNameKeyForNode, "twenty")
+	require.EqualError(t, err, "default value has wrong data type when int/long expected: string")
+	attrs, _ = n1.GetAttributes()
+	assert.Equal(t, 20, attrs[attrNameKeyForNode], "existing value is intact")
+	err = gr.SetAttribute(attrNameKeyForGraph, true)
+	require.Error(t, err)
+	require.Len(t, gml.Keys, 5)
 }
 
 func TestNode_GetAttributes(t *testing.T) {
@@ -819,6 +1164,171 @@ func TestNode_GetAttributes(t *testing.T) {
 	assert.Equal(t, attributes, nAttr)
 }
 
+func TestNode_GetAttributesWithSource(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "size", "", reflect.Int, 10)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "color", "", reflect.String, "red")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n, err := gr.AddNode(map[string]interface{}{"size": 5}, "")
+	require.NoError(t, err)
+
+	attrs, defaulted, err := n.GetAttributesWithSource()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 5, "color": "red"}, attrs)
+	assert.Equal(t, map[string]bool{"color": true}, defaulted)
+
+	// the explicit value equal to the default is not defaulted
+	err = n.SetAttribute("color", "red")
+	require.NoError(t, err)
+	attrs, defaulted, err = n.GetAttributesWithSource()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 5, "color": "red"}, attrs)
+	assert.Empty(t, defaulted)
+}
+
+func TestGraph_GetEdgeByID(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_parallel_edges.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	gr := gml.Graphs[0]
+
+	for _, e := range gr.Edges {
+		assert.Equal(t, e, gr.GetEdgeByID(e.ID))
+	}
+	assert.Nil(t, gr.GetEdgeByID("unknown"))
+
+	// check added and removed edges
+	n, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	edge, err := gr.AddEdge(gr.Nodes[0], n, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, edge, gr.GetEdgeByID(edge.ID))
+	err = gr.RemoveNode(n.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gr.GetEdgeByID(edge.ID))
+}
+
+func TestNode_OutgoingEdges(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	nodes := make([]*Node, 4)
+	for i := range nodes {
+		nodes[i], err = gr.AddNode(nil, "")
+		require.NoError(t, err)
+	}
+	e0, err := gr.AddEdge(nodes[0], nodes[1], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e1, err := gr.AddEdge(nodes[2], nodes[0], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	e2, err := gr.AddEdge(nodes[3], nodes[0], nil, EdgeDirectionUndirected, "")
+	require.NoError(t, err)
+	e3, err := gr.AddEdge(nodes[0], nodes[0], nil, EdgeDirectionUndirected, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []*Edge{e0, e3, e2}, nodes[0].OutgoingEdges())
+	assert.Equal(t, []*Edge{e1, e2, e3}, nodes[0].IncomingEdges())
+	assert.Equal(t, []*Edge{e0}, nodes[1].IncomingEdges())
+	assert.Empty(t, nodes[1].OutgoingEdges())
+	assert.Equal(t, []*Edge{e2}, nodes[3].OutgoingEdges())
+	assert.Equal(t, []*Edge{e2}, nodes[3].IncomingEdges())
+
+	// check that adjacency is updated
+	err = gr.RemoveNode(nodes[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*Edge{e2, e3}, nodes[0].IncomingEdges())
+
+	// check decoded graph
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	n := decoded.Graphs[0].GetNode(nodes[0].ID)
+	require.NotNil(t, n)
+	assert.Len(t, n.OutgoingEdges(), 3)
+	assert.Len(t, n.IncomingEdges(), 2)
+}
+
+func TestNode_Degree(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	nodes := make([]*Node, 3)
+	for i := range nodes {
+		nodes[i], err = gr.AddNode(nil, "")
+		require.NoError(t, err)
+	}
+	_, err = gr.AddEdge(nodes[0], nodes[1], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(nodes[0], nodes[0], nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(nodes[2], nodes[0], nil, EdgeDirectionUndirected, "")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		degree, inDegree, outDegree int
+	}{
+		{degree: 4, inDegree: 2, outDegree: 3},
+		{degree: 1, inDegree: 1, outDegree: 0},
+		{degree: 1, inDegree: 1, outDegree: 1},
+	}
+	for i, tc := range testCases {
+		assert.Equal(t, tc.degree, nodes[i].Degree(), "wrong degree of node: %s", nodes[i].ID)
+		assert.Equal(t, tc.inDegree, nodes[i].InDegree(), "wrong in-degree of node: %s", nodes[i].ID)
+		assert.Equal(t, tc.outDegree, nodes[i].OutDegree(), "wrong out-degree of node: %s", nodes[i].ID)
+	}
+}
+
+func TestGraph_AllowMultiEdges(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n3, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	first, err := gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "first route")
+	require.NoError(t, err)
+
+	// parallel edges rejected by default
+	_, err = gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "second route")
+	assert.True(t, errors.Is(err, ErrEdgeAlreadyAdded))
+
+	gr.AllowMultiEdges = true
+	second, err := gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "second route")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n2, n3, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Len(t, gr.Edges, 3)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first, gr.GetEdge(n1.ID, n2.ID))
+	assert.Equal(t, []*Edge{first, second}, gr.GetEdges(n1.ID, n2.ID))
+	assert.Empty(t, gr.GetEdges(n2.ID, n1.ID))
+
+	// check that parallel edges survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	edges := decoded.Graphs[0].GetEdges(n1.ID, n2.ID)
+	require.Len(t, edges, 2)
+	assert.Equal(t, edges[0], decoded.Graphs[0].GetEdge(n1.ID, n2.ID))
+	assert.Equal(t, "second route", edges[1].Description)
+}
+
 func TestGraph_AddEdge(t *testing.T) {
 	description := "test graph"
 	gml := NewGraphML("")
@@ -861,12 +1371,68 @@ func TestGraph_AddEdge(t *testing.T) {
 	// check error by adding the same node
 	edge, err = gr.AddEdge(n1, n2, attributes, EdgeDirectionDefault, description)
 	require.EqualError(t, err, "edge already added to the graph")
+	assert.True(t, errors.Is(err, ErrEdgeAlreadyAdded))
 
 	// check no error for directed node backward
 	edge, err = gr.AddEdge(n2, n1, attributes, EdgeDirectionDefault, description)
 	assert.NoError(t, err)
 }
 
+func TestEdge_Direction(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="g0" edgedefault="undirected">
+		<node id="n0"/><node id="n1"/><node id="n2"/>
+		<edge id="e0" source="n0" target="n1"/>
+		<edge id="e1" source="n1" target="n2" directed="true"/>
+		<edge id="e2" source="n2" target="n0" directed="1"/>
+	</graph>
+	<graph id="g1">
+		<node id="n0"/><node id="n1"/>
+		<edge id="e0" source="n0" target="n1"/>
+		<edge id="e1" source="n1" target="n0" directed="false"/>
+	</graph>
+</graphml>`
+	gml := NewGraphML("")
+	err := gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+	require.Len(t, gml.Graphs, 2)
+	expected := [][]EdgeDirection{
+		{EdgeDirectionUndirected, EdgeDirectionDirected, EdgeDirectionDirected},
+		{EdgeDirectionDirected, EdgeDirectionUndirected},
+	}
+	for i, gr := range gml.Graphs {
+		require.Len(t, gr.Edges, len(expected[i]))
+		for j, e := range gr.Edges {
+			assert.Equal(t, expected[i][j], e.Direction(), "wrong direction of edge: %s in graph: %s", e.ID, gr.ID)
+		}
+	}
+	assert.Len(t, gml.Graphs[0].GetNode("n1").OutgoingEdges(), 2)
+	assert.Len(t, gml.Graphs[0].GetNode("n2").OutgoingEdges(), 1)
+
+	// round trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	for i, gr := range decoded.Graphs {
+		for j, e := range gr.Edges {
+			assert.Equal(t, expected[i][j], e.Direction())
+		}
+	}
+
+	// added edges
+	n0, n1 := gml.Graphs[1].GetNode("n0"), gml.Graphs[1].GetNode("n1")
+	gml.Graphs[1].AllowMultiEdges = true
+	edge, err := gml.Graphs[1].AddEdge(n0, n1, nil, EdgeDirectionUndirected, "")
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDirectionUndirected, edge.Direction())
+	edge, err = gml.Graphs[1].AddEdge(n0, n1, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDirectionDirected, edge.Direction())
+}
+
 func TestEdge_GetAttributes(t *testing.T) {
 	description := "test graph"
 	gml := NewGraphML("")
@@ -942,6 +1508,48 @@ func TestGraphML_stringValueIfSupported(t *testing.T) {
 	assert.Equal(t, testString, res)
 }
 
+func TestGraphML_SpecialFloatValues(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "weight", "", reflect.Float32, float32(math.Inf(1)))
+	require.NoError(t, err)
+	assert.Equal(t, "INF", gml.GetKey("weight", KeyForNode).DefaultValue)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	values := []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1.5}
+	for _, v := range values {
+		_, err = gr.AddNode(map[string]interface{}{"score": v}, "")
+		require.NoError(t, err)
+	}
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	for _, token := range []string{">NaN</data>", ">INF</data>", ">-INF</data>", "<default>INF</default>"} {
+		assert.Contains(t, outBuf.String(), token)
+	}
+
+	// check that values survive round-trip
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	for i, v := range values {
+		value, ok, err := decoded.Graphs[0].Nodes[i].GetAttribute("score")
+		require.NoError(t, err)
+		require.True(t, ok)
+		if math.IsNaN(v) {
+			assert.True(t, math.IsNaN(value.(float64)))
+		} else {
+			assert.Equal(t, v, value)
+		}
+	}
+	value, ok, err := decoded.Graphs[0].Nodes[len(values)].GetAttribute("weight")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, float32(math.Inf(1)), value)
+}
+
 func TestGraphML_AutomaticKeysGeneration(t *testing.T) {
 	graphFile, err := os.Open("../data/test_graph_1_indexed.xml")
 	require.NoError(t, err, "failed to open file")
@@ -979,3 +1587,1384 @@ func TestGraphML_AutomaticKeysGeneration(t *testing.T) {
 	require.NoError(t, err, "failed to add edge")
 	assert.Equal(t, "e2", edge.ID)
 }
+
+func TestGraphML_KeysForElement(t *testing.T) {
+	gml := NewGraphML("")
+	weight, err := gml.RegisterKey(KeyForAll, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	name, err := gml.RegisterKey(KeyForNode, "name", "", reflect.String, nil)
+	require.NoError(t, err)
+	label, err := gml.RegisterKey(KeyForEdge, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+	age, err := gml.RegisterKey(KeyForNode, "age", "", reflect.Int, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*Key{weight, name, age}, gml.KeysForElement(KeyForNode))
+	assert.Equal(t, []*Key{weight, label}, gml.KeysForElement(KeyForEdge))
+	assert.Equal(t, []*Key{weight}, gml.KeysForElement(KeyForGraphML))
+	assert.Empty(t, NewGraphML("").KeysForElement(KeyForNode))
+}
+
+func TestGraphML_AttributeNamesForTarget(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForAll, "weight", "", reflect.Float64, nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "name", "", reflect.String, nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "age", "", reflect.Int, nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForEdge, "label", "", reflect.String, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"age", "name", "weight"}, gml.AttributeNamesForTarget(KeyForNode))
+	assert.Equal(t, []string{"label", "weight"}, gml.AttributeNamesForTarget(KeyForEdge))
+	assert.Equal(t, []string{"weight"}, gml.AttributeNamesForTarget(KeyForGraph))
+	assert.Empty(t, NewGraphML("").AttributeNamesForTarget(KeyForNode))
+}
+
+func TestGraphML_OrderedAttributeNames(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<key id="d0" for="node" attr.name="zeta" attr.type="string"/>
+	<key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+	<key id="d2" for="all" attr.name="alpha" attr.type="int"/>
+	<key id="d3" for="node" attr.name="mu" attr.type="string"/>
+</graphml>`
+	gml := NewGraphML("")
+	err := gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"zeta", "alpha", "mu"}, gml.OrderedAttributeNames(KeyForNode))
+	assert.Equal(t, []string{"weight", "alpha"}, gml.OrderedAttributeNames(KeyForEdge))
+	assert.Equal(t, []string{"alpha"}, gml.OrderedAttributeNames(KeyForGraph))
+
+	_, err = gml.RegisterKey(KeyForNode, "beta", "", reflect.String, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zeta", "alpha", "mu", "beta"}, gml.OrderedAttributeNames(KeyForNode))
+	assert.Equal(t, []string{"alpha", "beta", "mu", "zeta"}, gml.AttributeNamesForTarget(KeyForNode))
+}
+
+func TestGraphML_Counts(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_1_indexed.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+
+	gr, err := gml.AddGraph("test graph", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, gr.NodeCount())
+	assert.Equal(t, 0, gr.EdgeCount())
+	n1, err := gr.AddNode(map[string]interface{}{"test": "value"}, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+	n3, err := gr.AddNode(nil, "#3")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n2, n3, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, gr.NodeCount())
+	assert.Equal(t, 2, gr.EdgeCount())
+
+	graphs, nodes, edges, keys := gml.Counts()
+	assert.Equal(t, 2, graphs)
+	assert.Equal(t, 5, nodes)
+	assert.Equal(t, 3, edges)
+	assert.Equal(t, 2, keys)
+
+	// the nested graphs are counted
+	nested, err := n1.AddGraph("nested", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = nested.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	graphs, nodes, edges, _ = gml.Counts()
+	assert.Equal(t, 3, graphs)
+	assert.Equal(t, 7, nodes)
+	assert.Equal(t, 4, edges)
+}
+
+func TestGraphML_coerceFloatToInt(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "count", "", reflect.Int, 1.0)
+	require.NoError(t, err, "whole float default value should be accepted")
+	_, err = gml.RegisterKey(KeyForNode, "size", "", reflect.Int64, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	// whole numbers decoded from JSON as float64
+	node, err := gr.AddNode(map[string]interface{}{"count": float64(42), "size": float32(-7)}, "#1")
+	require.NoError(t, err)
+	attrs, err := node.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": 42, "size": int64(-7)}, attrs)
+
+	// fractional values are rejected rather than truncated
+	_, err = gr.AddNode(map[string]interface{}{"count": 42.5}, "#2")
+	assert.Error(t, err)
+	err = node.SetAttribute("count", math.NaN())
+	assert.Error(t, err)
+	err = node.SetAttribute("size", math.Inf(1))
+	assert.Error(t, err)
+
+	// strict mode
+	gml.StrictNumericTypes = true
+	err = node.SetAttribute("count", float64(10))
+	assert.Error(t, err)
+	_, err = gml.RegisterKey(KeyForEdge, "count", "", reflect.Int, 1.0)
+	assert.Error(t, err)
+}
+
+func TestGraphML_RegisteredKeyTypeWins(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "id", "", reflect.Int64, nil)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "weight", "", reflect.Float64, 1)
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "ratio", "", reflect.Float32, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	node, err := gr.AddNode(map[string]interface{}{"id": 42, "weight": int32(3), "ratio": uint8(2)}, "")
+	require.NoError(t, err)
+	attrs, err := node.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(42), "weight": 3.0, "ratio": float32(2)}, attrs)
+	assert.Equal(t, LongType, gml.GetKey("id", KeyForNode).KeyType)
+	assert.Equal(t, "1", gml.GetKey("weight", KeyForNode).DefaultValue)
+
+	// values which can not be represented exactly are rejected
+	err = node.SetAttribute("weight", int64(1)<<53+1)
+	assert.EqualError(t, err, "integer value can not be stored as double exactly: 9007199254740993")
+	err = node.SetAttribute("ratio", 1<<24+1)
+	assert.Error(t, err)
+	err = node.SetAttribute("id", "42")
+	assert.Error(t, err)
+	err = node.SetAttribute("id", uint64(math.MaxUint64))
+	assert.EqualError(t, err, "integer value is out of range of long: 18446744073709551615")
+	_, err = gml.RegisterKey(KeyForEdge, "id", "", reflect.Int, uint(math.MaxUint64))
+	assert.EqualError(t, err, "integer value is out of range of int: 18446744073709551615")
+
+	// strict mode
+	gml.StrictNumericTypes = true
+	err = node.SetAttribute("weight", 2)
+	assert.Error(t, err)
+}
+
+func TestGraph_AddEdgeByID(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "#1")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "#2")
+	require.NoError(t, err)
+
+	attributes := map[string]interface{}{"weight": 1.5}
+	edge, err := gr.AddEdgeByID(n1.ID, n2.ID, attributes, EdgeDirectionDefault, "test edge", false)
+	require.NoError(t, err)
+	assert.Same(t, n1, edge.SourceNode())
+	assert.Same(t, n2, edge.TargetNode())
+	assert.Equal(t, "test edge", edge.Description)
+	attrs, err := edge.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, attributes, attrs)
+
+	// missing nodes
+	edge, err = gr.AddEdgeByID(n1.ID, "a", nil, EdgeDirectionDefault, "", false)
+	assert.EqualError(t, err, "node not found: a")
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+	assert.Nil(t, edge)
+	assert.Len(t, gr.Nodes, 2)
+
+	edge, err = gr.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	assert.Len(t, gr.Nodes, 4)
+	require.NotNil(t, gr.GetNode("a"))
+	require.NotNil(t, gr.GetNode("b"))
+	assert.Same(t, gr.GetNode("a"), edge.SourceNode())
+	assert.Same(t, gr.GetNode("b"), edge.TargetNode())
+
+	// duplicate edge
+	_, err = gr.AddEdgeByID(n1.ID, n2.ID, nil, EdgeDirectionDefault, "", false)
+	assert.EqualError(t, err, "edge already added to the graph")
+
+	// the nodes created are removed when edge can not be added
+	_, err = gr.AddEdgeByID("c", "", nil, EdgeDirectionDefault, "", true)
+	assert.EqualError(t, err, "node ID must be provided")
+	_, err = gr.AddEdgeByID("c", "d", map[string]interface{}{"weight": "heavy"}, EdgeDirectionDefault, "", true)
+	assert.Error(t, err)
+	assert.Len(t, gr.Nodes, 4)
+	assert.Nil(t, gr.GetNode("c"))
+	assert.Nil(t, gr.GetNode("d"))
+	assert.Len(t, gr.Edges, 2)
+}
+
+func TestGraph_AddWithID(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	src, err := gr.AddNodeWithID("user:1", map[string]interface{}{"name": "one"}, "#1")
+	require.NoError(t, err)
+	assert.Equal(t, "user:1", src.ID)
+	assert.Equal(t, "#1", src.Description)
+	assert.Same(t, src, gr.GetNode("user:1"))
+	tgt, err := gr.AddNodeWithID("user:2", nil, "")
+	require.NoError(t, err)
+	// auto-generated IDs are still available
+	n, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "n2", n.ID)
+
+	edge, err := gr.AddEdgeWithID("follows:1", src, tgt, map[string]interface{}{"weight": 1.5}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, "follows:1", edge.ID)
+	assert.Same(t, edge, gr.GetEdgeByID("follows:1"))
+	assert.Same(t, edge, gr.GetEdge(src.ID, tgt.ID))
+	edge, err = gr.AddEdge(tgt, n, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, "e1", edge.ID)
+
+	// collisions
+	_, err = gr.AddNodeWithID("user:1", nil, "")
+	assert.EqualError(t, err, "node with ID: user:1 already exists in graph: g0")
+	_, err = gr.AddNodeWithID("", nil, "")
+	assert.EqualError(t, err, "node ID must be provided")
+	_, err = gr.AddEdgeWithID("follows:1", tgt, src, nil, EdgeDirectionDefault, "")
+	assert.EqualError(t, err, "edge with ID: follows:1 already exists in graph: g0")
+	_, err = gr.AddEdgeWithID("", tgt, src, nil, EdgeDirectionDefault, "")
+	assert.EqualError(t, err, "edge ID must be provided")
+	_, err = gr.AddEdgeWithID("follows:2", src, tgt, nil, EdgeDirectionDefault, "")
+	assert.True(t, errors.Is(err, ErrEdgeAlreadyAdded))
+	assert.Len(t, gr.Nodes, 3)
+	assert.Len(t, gr.Edges, 2)
+
+	// IDs survive round-trip
+	decoded := NewGraphML("")
+	str, err := gml.EncodeToString(false)
+	require.NoError(t, err)
+	err = decoded.DecodeString(str)
+	require.NoError(t, err)
+	assert.NotNil(t, decoded.Graphs[0].GetNode("user:2"))
+	assert.NotNil(t, decoded.Graphs[0].GetEdgeByID("follows:1"))
+}
+
+func TestData_typedValueCache(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	attributes := map[string]interface{}{
+		"double":  0.1 + 0.2,
+		"float":   float32(1.0 / 3.0),
+		"bool":    true,
+		"integer": 42,
+		"long":    int64(-42),
+		"string":  "string data",
+		"small":   int8(8),
+	}
+	node, err := gr.AddNode(attributes, "#1")
+	require.NoError(t, err)
+	for _, d := range node.Data {
+		key := gml.keysById[d.Key]
+		if key.Name == "small" {
+			// cached after conversion to the Go type of key
+			assert.Equal(t, 8, d.typed)
+		} else {
+			assert.Equal(t, attributes[key.Name], d.typed, "wrong cached value: %s", key.Name)
+		}
+	}
+	attrs, err := node.GetAttributes()
+	require.NoError(t, err)
+	attributes["small"] = 8
+	assert.Equal(t, attributes, attrs)
+
+	// the cached value is the same as parsed one
+	for _, d := range node.Data {
+		key := gml.keysById[d.Key]
+		parsed, err := valueByType(d.Value, key.KeyType, StringType)
+		require.NoError(t, err)
+		assert.Equal(t, parsed, attrs[key.Name])
+	}
+
+	// the cached value is ignored when value changed directly
+	for _, d := range node.Data {
+		if gml.keysById[d.Key].Name == "integer" {
+			d.Value = "24"
+		}
+	}
+	attrs, err = node.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, 24, attrs["integer"])
+}
+
+func TestGraphML_StringKeyDefaults(t *testing.T) {
+	const document = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<key id="d0" for="all" attr.name="status" attr.type="string"><default>active</default></key>
+	<key id="d1" for="node" attr.name="label" attr.type="string"/>
+	<graph id="g0" edgedefault="directed">
+		<node id="n0"/>
+		<node id="n1"><data key="d0">retired</data></node>
+		<edge id="e0" source="n0" target="n1"/>
+	</graph>
+</graphml>`
+	gml := NewGraphML("")
+	err := gml.Decode(strings.NewReader(document))
+	require.NoError(t, err)
+	gr := gml.Graphs[0]
+
+	attrs, err := gr.Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "active", "label": ""}, attrs)
+	assert.True(t, gr.Nodes[0].IsAttributeDefaulted("status"))
+	attrs, err = gr.Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "retired", attrs["status"])
+	for _, attributes := range []func() (map[string]interface{}, error){gml.GetAttributes, gr.GetAttributes, gr.Edges[0].GetAttributes} {
+		attrs, err = attributes()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"status": "active"}, attrs)
+	}
+	value, found, err := gr.Edges[0].GetAttribute("status")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "active", value)
+
+	// the default is emitted and data not synthesized
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<default>active</default>")
+	assert.Contains(t, outBuf.String(), "<node id=\"n0\"></node>")
+}
+
+func TestNode_AttributesWithKeys(t *testing.T) {
+	gml := NewGraphML("")
+	color, err := gml.RegisterKey(KeyForNode, "color", "node color", reflect.String, "red")
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, map[string]interface{}{"name": "graph"})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"weight": 1.5}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"color": "blue"}, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"weight": 2}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	weight := gml.GetKey("weight", KeyForNode)
+	require.NotNil(t, weight)
+
+	views, err := n1.AttributesWithKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []AttributeView{
+		{Key: color, Value: "red", Defaulted: true},
+		{Key: weight, Value: 1.5},
+	}, views)
+
+	views, err = n2.AttributesWithKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []AttributeView{{Key: color, Value: "blue"}}, views)
+
+	// other elements
+	views, err = gr.Edges[0].AttributesWithKeys()
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, KeyForEdge, views[0].Key.Target)
+	assert.Equal(t, IntType, views[0].Key.KeyType)
+	assert.Equal(t, 2, views[0].Value)
+
+	views, err = gr.AttributesWithKeys()
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "name", views[0].Key.Name)
+	assert.Equal(t, "graph", views[0].Value)
+
+	views, err = gml.AttributesWithKeys()
+	require.NoError(t, err)
+	assert.Empty(t, views)
+}
+
+func TestNode_RawAttribute(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "color", "", reflect.String, "red")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForAll, "weight", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, map[string]interface{}{"weight": 2.0})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"color": ""}, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(map[string]interface{}{"weight": 2.5}, "")
+	require.NoError(t, err)
+	edge, err := gr.AddEdge(n1, n2, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+
+	value, present := n1.RawAttribute("color")
+	assert.True(t, present, "explicitly set to empty")
+	assert.Equal(t, "", value)
+	value, present = n2.RawAttribute("color")
+	assert.False(t, present, "not set")
+	assert.Equal(t, "", value)
+	attrs, err := n2.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "red", attrs["color"])
+
+	value, present = n2.RawAttribute("weight")
+	assert.True(t, present)
+	assert.Equal(t, "2.5", value)
+	gml.InheritGraphAttributes = true
+	_, present = n1.RawAttribute("weight")
+	assert.False(t, present, "graph attributes are not inherited")
+
+	// other elements
+	value, present = gr.RawAttribute("weight")
+	assert.True(t, present)
+	assert.Equal(t, "2", value)
+	_, present = edge.RawAttribute("weight")
+	assert.False(t, present)
+	_, present = gml.RawAttribute("weight")
+	assert.False(t, present)
+}
+
+func TestNode_IsAttributeDefaulted(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	graph := gml.Graphs[0]
+
+	_, err = gml.RegisterKey(KeyForNode, "color", "", reflect.String, "red")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForNode, "size", "", reflect.Int, nil)
+	require.NoError(t, err)
+	node, err := graph.AddNode(map[string]interface{}{"integer": 42}, "")
+	require.NoError(t, err)
+
+	assert.True(t, node.IsAttributeDefaulted("color"))
+	assert.False(t, node.IsAttributeDefaulted("integer"))
+	assert.False(t, node.IsAttributeDefaulted("size"), "no default value")
+	assert.False(t, node.IsAttributeDefaulted("unknown"))
+	attrs, err := node.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "red", attrs["color"])
+
+	err = node.SetAttribute("color", "red")
+	require.NoError(t, err)
+	assert.False(t, node.IsAttributeDefaulted("color"), "explicitly set to default value")
+
+	// other elements
+	assert.False(t, graph.IsAttributeDefaulted("bool"))
+	assert.False(t, graph.Edges[0].IsAttributeDefaulted("bool"))
+	assert.False(t, gml.IsAttributeDefaulted("bool"))
+	_, err = gml.RegisterKey(KeyForAll, "common", "", reflect.Float64, 1.0)
+	require.NoError(t, err)
+	assert.True(t, graph.IsAttributeDefaulted("common"))
+	assert.True(t, graph.Edges[0].IsAttributeDefaulted("common"))
+	assert.True(t, gml.IsAttributeDefaulted("common"))
+}
+
+func TestGraph_AddNodes(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "existing")
+	require.NoError(t, err)
+
+	specs := []NodeSpec{
+		{Attributes: map[string]interface{}{"name": "first", "weight": 1.5}, Description: "#1"},
+		{Attributes: nil, Description: "#2"},
+		{Attributes: map[string]interface{}{"name": "third"}, Description: "#3"},
+	}
+	nodes, err := gr.AddNodes(specs)
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	require.Len(t, gr.Nodes, 4)
+	for i, n := range nodes {
+		assert.Equal(t, fmt.Sprintf("n%d", i+1), n.ID)
+		assert.Equal(t, specs[i].Description, n.Description)
+		assert.Equal(t, n, gr.GetNode(n.ID))
+	}
+	attrs, err := nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "first", "weight": 1.5}, attrs)
+	assert.Len(t, gml.Keys, 2)
+
+	// invalid spec
+	specs = []NodeSpec{
+		{Attributes: map[string]interface{}{"name": "fourth", "color": "red"}},
+		{Attributes: map[string]interface{}{"weight": "heavy"}},
+	}
+	nodes, err = gr.AddNodes(specs)
+	assert.EqualError(t, err, "failed to create node at index: 1, reason: default value has wrong data type when float/double expected: string")
+	assert.Nil(t, nodes)
+	assert.Len(t, gr.Nodes, 4)
+	// the key registered for the preceding specification is kept
+	assert.Len(t, gml.Keys, 3)
+	assert.NotNil(t, gml.GetKey("color", KeyForNode))
+}
+
+func TestGraphML_SetSerializer(t *testing.T) {
+	gml := NewGraphML("")
+	gml.SetSerializer(IntType, func(value interface{}) (string, error) {
+		return fmt.Sprintf("0x%x", value), nil
+	})
+	gml.SetDeserializer(IntType, func(value string) (interface{}, error) {
+		res, err := strconv.ParseInt(value, 0, 64)
+		return int(res), err
+	})
+	_, err := gml.RegisterKey(KeyForNode, "mask", "", reflect.Int, 255)
+	require.NoError(t, err)
+	assert.Equal(t, "0xff", gml.GetKey("mask", KeyForNode).DefaultValue)
+
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n, err := gr.AddNode(map[string]interface{}{"mask": 16, "weight": 1.5}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "0x10", n.Data[0].Value)
+	assert.Equal(t, "1.5", n.Data[1].Value, "default serialization for other types")
+
+	// decode with custom deserializer
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	decoded.SetDeserializer(IntType, func(value string) (interface{}, error) {
+		res, err := strconv.ParseInt(value, 0, 64)
+		return int(res), err
+	})
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"mask": 16, "weight": 1.5}, attrs)
+
+	// restore defaults
+	decoded.SetDeserializer(IntType, nil)
+	_, err = decoded.Graphs[0].Nodes[0].GetAttributes()
+	assert.Error(t, err)
+	gml.SetSerializer(IntType, nil)
+	err = n.SetAttribute("mask", 16)
+	require.NoError(t, err)
+	assert.Equal(t, "16", n.Data[0].Value)
+
+	// serializer error
+	gml.SetSerializer(DoubleType, func(value interface{}) (string, error) {
+		return "", errors.New("not supported")
+	})
+	_, err = gr.AddNode(map[string]interface{}{"weight": 2.5}, "")
+	assert.EqualError(t, err, "not supported")
+}
+
+func TestGraphML_FindOrphanedData(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	assert.Empty(t, gml.FindOrphanedData())
+
+	// remove key improperly
+	key := gml.GetKey("bool", KeyForGraph)
+	require.NotNil(t, key)
+	delete(gml.keysById, key.ID)
+
+	orphans := gml.FindOrphanedData()
+	require.Len(t, orphans, 1)
+	assert.Equal(t, KeyForGraph, orphans[0].Element)
+	assert.Equal(t, gml.Graphs[0].ID, orphans[0].GraphID)
+	assert.Equal(t, gml.Graphs[0].ID, orphans[0].ElementID)
+	assert.Equal(t, key.ID, orphans[0].Data.Key)
+	_, err = gml.Graphs[0].GetAttributes()
+	assert.Error(t, err)
+}
+
+func TestGraphML_Validate(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	assert.Empty(t, gml.Validate())
+
+	// break the document
+	gr := gml.Graphs[0]
+	gml.Keys = append(gml.Keys, &Key{ID: gml.Keys[0].ID, Name: "duplicate"})
+	gr.Data = append(gr.Data, &Data{Key: "d42"})
+	gr.EdgeDefault = "bidirected"
+	gr.Nodes = append(gr.Nodes, &Node{ID: gr.Nodes[0].ID})
+	gr.Edges[0].Target = "n42"
+	gml.Graphs = append(gml.Graphs, &Graph{ID: gr.ID, EdgeDefault: edgeDirectionDirected})
+
+	errs := gml.Validate()
+	require.Len(t, errs, 6)
+	assert.EqualError(t, errs[0], "duplicate key ID: "+gml.Keys[0].ID)
+	assert.EqualError(t, errs[1], "wrong edge default: bidirected of graph: g0")
+	assert.EqualError(t, errs[2], "data of graph: g0 refers undeclared key: d42")
+	assert.EqualError(t, errs[3], "duplicate node ID: n0 in graph: g0")
+	assert.EqualError(t, errs[4], "target node: n42 of edge: e0 not found")
+	assert.EqualError(t, errs[5], "duplicate graph ID: g0")
+}
+
+func TestGraphML_Uint64Attributes(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"hash": uint64(math.MaxUint64), "count": uint(42)}, "")
+	require.NoError(t, err)
+	for _, name := range []string{"hash", "count"} {
+		assert.Equal(t, LongType, gml.GetKey(name, KeyForNode).KeyType)
+		assert.Equal(t, KeyFormatUint64, gml.GetKey(name, KeyForNode).Format)
+	}
+	_, err = gr.AddNode(map[string]interface{}{"count": -1}, "")
+	assert.Error(t, err)
+
+	// the plain long key holds signed values only
+	_, err = gml.RegisterKey(KeyForNode, "size", "", reflect.Int64, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"hash": uint64(7), "count": uint(1), "size": int64(-42)}, "")
+	require.NoError(t, err)
+
+	// check that values survive round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	attrs, err := decoded.Graphs[0].Nodes[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hash": uint64(math.MaxUint64), "count": uint64(42)}, attrs)
+	attrs, err = decoded.Graphs[0].Nodes[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hash": uint64(7), "count": uint64(1), "size": int64(-42)}, attrs)
+
+	// the default value
+	key, err := gml.RegisterUint64Key(KeyForEdge, "hash", "", uint64(math.MaxUint64))
+	require.NoError(t, err)
+	assert.Equal(t, "18446744073709551615", key.DefaultValue)
+	_, err = gml.RegisterUint64Key(KeyForEdge, "count", "", -1)
+	assert.Error(t, err)
+
+	// the values out of range
+	_, err = valueByType("18446744073709551615", LongType, StringType)
+	assert.Error(t, err)
+	_, err = valueByFormat("-1", KeyFormatUint64)
+	assert.Error(t, err)
+	_, err = valueByType("18446744073709551616", LongType, StringType)
+	assert.Error(t, err)
+	_, err = valueByType("-9223372036854775809", LongType, StringType)
+	assert.Error(t, err)
+	_, err = valueByType("9223372036854775808", IntType, StringType)
+	assert.Error(t, err)
+}
+
+func TestGraphML_Uint64Attributes_ExplicitKey(t *testing.T) {
+	gml := NewGraphML("")
+	key, err := gml.RegisterKey(KeyForNode, "p", "", reflect.Uint64, nil)
+	require.NoError(t, err)
+	assert.Equal(t, KeyFormatUint64, key.Format)
+	_, err = gml.RegisterKey(KeyForNode, "size", "", reflect.Int64, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n, err := gr.AddNode(map[string]interface{}{"p": uint64(math.MaxUint64)}, "")
+	require.NoError(t, err)
+	attrs, err := n.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"p": uint64(math.MaxUint64)}, attrs)
+
+	// the plain long key rejects the unsigned values above math.MaxInt64
+	_, err = gr.AddNode(map[string]interface{}{"size": uint64(math.MaxUint64)}, "")
+	assert.EqualError(t, err, "integer value is out of range of long: 18446744073709551615")
+	gml.StrictNumericTypes = true
+	_, err = gr.AddNode(map[string]interface{}{"size": uint64(math.MaxUint64)}, "")
+	assert.EqualError(t, err, "unsigned value is out of range of long: 18446744073709551615")
+	gml.StrictNumericTypes = false
+	_, err = gml.RegisterKey(KeyForEdge, "size", "", reflect.Int64, uint64(math.MaxUint64))
+	assert.Error(t, err)
+	assert.Len(t, gr.Nodes, 1)
+}
+
+func TestGraphML_valueByType_Lenient(t *testing.T) {
+	res, err := valueByType("+5", IntType, StringType)
+	require.NoError(t, err)
+	assert.Equal(t, 5, res)
+	res, err = valueByType(" 5 ", LongType, StringType)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res)
+	res, err = valueByType("+3.14", DoubleType, StringType)
+	require.NoError(t, err)
+	assert.Equal(t, 3.14, res)
+	res, err = valueByType("\n\ttrue ", BooleanType, StringType)
+	require.NoError(t, err)
+	assert.Equal(t, true, res)
+	res, err = valueByType(" text ", StringType, StringType)
+	require.NoError(t, err)
+	assert.Equal(t, " text ", res, "strings are not trimmed")
+
+	_, err = valueByType("+ 5", IntType, StringType)
+	assert.Error(t, err)
+}
+
+func TestNode_Path(t *testing.T) {
+	gml := NewGraphML("")
+	gr0, err := gml.AddGraph("first", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	gr1, err := gml.AddGraph("second", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n0, err := gr0.AddNode(nil, "")
+	require.NoError(t, err)
+	n1, err := gr1.AddNode(nil, "")
+	require.NoError(t, err)
+	require.Equal(t, n0.ID, n1.ID)
+
+	assert.Equal(t, "g0/n0", n0.Path())
+	assert.Equal(t, "g1/n0", n1.Path())
+	assert.Equal(t, n0, gml.NodeByPath(n0.Path()))
+	assert.Equal(t, n1, gml.NodeByPath(n1.Path()))
+
+	assert.Nil(t, gml.NodeByPath("g0/n1"))
+	assert.Nil(t, gml.NodeByPath("g2/n0"))
+	assert.Nil(t, gml.NodeByPath("n0"))
+	assert.Nil(t, gml.NodeByPath("g0/n0/g1"))
+}
+
+func TestGraphML_Decode_NestedGraphs(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_nested.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	require.Len(t, gml.Graphs, 1)
+	n0 := gml.Graphs[0].GetNode("n0")
+	require.NotNil(t, n0)
+	require.Len(t, n0.Graphs, 1)
+	nested := n0.Graphs[0]
+	assert.Equal(t, n0, nested.ParentNode())
+	assert.Nil(t, gml.Graphs[0].ParentNode())
+	assert.Equal(t, nested, gml.GetGraph("n0:"))
+	assert.Len(t, nested.Nodes, 2)
+	assert.NotNil(t, nested.GetEdge("n0::n0", "n0::n1"))
+	attrs, err := nested.GetNode("n0::n0").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cluster": "leaf"}, attrs)
+
+	// check paths of nested nodes
+	deep := gml.GetGraph("n0::n1:").GetNode("n0::n1::n0")
+	require.NotNil(t, deep)
+	assert.Equal(t, "G/n0/n0:/n0::n1/n0::n1:/n0::n1::n0", deep.Path())
+	assert.Equal(t, deep, gml.NodeByPath(deep.Path()))
+	assert.Nil(t, gml.NodeByPath("G/n1/n0:/n0::n1"))
+
+	// add nested graph
+	inner, err := gml.Graphs[0].GetNode("n1").AddGraph("inner", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "g1", inner.ID)
+	_, err = inner.AddNode(nil, "")
+	require.NoError(t, err)
+
+	// check that hierarchy survives round-trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.GetGraph("n0::n1:"))
+	assert.NotNil(t, decoded.NodeByPath(deep.Path()))
+	assert.NotNil(t, decoded.NodeByPath("G/n1/g1/n0"))
+	assert.Empty(t, decoded.Validate())
+
+	// remove hierarchical node
+	err = decoded.Graphs[0].RemoveNode("n0")
+	require.NoError(t, err)
+	assert.Nil(t, decoded.GetGraph("n0::n1:"))
+}
+
+func TestNode_GetAttributes_InheritGraphAttributes(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForAll, "color", "", reflect.String, "black")
+	require.NoError(t, err)
+	_, err = gml.RegisterKey(KeyForAll, "size", "", reflect.Int, 1)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("styled", EdgeDirectionDirected, map[string]interface{}{"color": "red", "size": 10, "name": "graph"})
+	require.NoError(t, err)
+	n0, err := gr.AddNode(map[string]interface{}{"size": 5}, "")
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	// key defaults without inheritance
+	attrs, err := n1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "black", "size": 1}, attrs)
+
+	gml.InheritGraphAttributes = true
+	attrs, err = n0.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red", "size": 5}, attrs)
+	attrs, err = n1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red", "size": 10}, attrs, "graph specific attribute is not inherited")
+	assert.Len(t, n1.Data, 0)
+}
+
+func TestGraphML_ResolveData(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForNode, "size", "", reflect.Int, 10)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n, err := gr.AddNode(map[string]interface{}{"size": 5, "weight": 1.5}, "")
+	require.NoError(t, err)
+
+	key, value, err := gml.ResolveData(n.Data[0])
+	require.NoError(t, err)
+	assert.Equal(t, gml.KeyByID("d0"), key)
+	assert.Equal(t, "size", key.Name)
+	assert.Equal(t, 5, value)
+	key, value, err = gml.ResolveData(n.Data[1])
+	require.NoError(t, err)
+	assert.Equal(t, "weight", key.Name)
+	assert.Equal(t, 1.5, value)
+
+	// default value
+	_, value, err = gml.ResolveData(&Data{Key: "d0"})
+	require.NoError(t, err)
+	assert.Equal(t, 10, value)
+
+	// errors
+	_, _, err = gml.ResolveData(&Data{Key: "d1"})
+	assert.EqualError(t, err, "data has no value and key id: d1 has no default value")
+	_, _, err = gml.ResolveData(&Data{Key: "d0", Value: "big"})
+	assert.Error(t, err)
+	_, _, err = gml.ResolveData(&Data{Key: "unknown", Value: "1"})
+	assert.EqualError(t, err, "failed to find attribute name/type by id: unknown")
+	assert.Nil(t, gml.KeyByID("unknown"))
+}
+
+func TestGraphML_SkipUnknownKeys(t *testing.T) {
+	gml := NewGraphML("")
+	err := gml.DecodeString(`<graphml>
+<key id="d0" for="node" attr.name="color" attr.type="string"/>
+<graph id="g0" edgedefault="directed">
+<node id="n0"><data key="d0">red</data><data key="d1">orphan</data></node>
+</graph>
+</graphml>`)
+	require.NoError(t, err)
+	n := gml.Graphs[0].Nodes[0]
+
+	// strict by default
+	_, err = n.GetAttributes()
+	assert.EqualError(t, err, "failed to find attribute name/type by id: d1")
+	_, err = n.AttributesWithKeys()
+	assert.Error(t, err)
+
+	gml.SkipUnknownKeys = true
+	attrs, err := n.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, attrs)
+	views, err := n.AttributesWithKeys()
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "color", views[0].Key.Name)
+	// the orphan data is kept
+	assert.Len(t, n.Data, 2)
+}
+
+func TestGraph_RemoveNode(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	for _, pair := range [][2]string{{"n0", "n1"}, {"n1", "n2"}, {"n2", "n0"}, {"n2", "n3"}, {"n1", "n1"}} {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], nil, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+	n0 := gr.GetNode("n0")
+
+	err = gr.RemoveNode("n1")
+	require.NoError(t, err)
+	require.Len(t, gr.Nodes, 3)
+	assert.Equal(t, n0, gr.Nodes[0])
+	assert.Equal(t, "n2", gr.Nodes[1].ID)
+	assert.Equal(t, "n3", gr.Nodes[2].ID)
+	assert.Nil(t, gr.GetNode("n1"))
+	require.Len(t, gr.Edges, 2)
+	assert.Equal(t, "e2", gr.Edges[0].ID)
+	assert.Equal(t, "e3", gr.Edges[1].ID)
+	assert.Nil(t, gr.GetEdge("n0", "n1"))
+	assert.Nil(t, gr.GetEdge("n1", "n1"))
+	assert.NotNil(t, gr.GetEdge("n2", "n0"))
+
+	// new elements get unique IDs
+	n, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "n4", n.ID)
+
+	err = gr.RemoveNode("n1")
+	assert.EqualError(t, err, "node not found: n1")
+}
+
+func TestEdge_Ports(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_ports.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	gr := gml.Graphs[0]
+	n0, n1 := gr.GetNode("n0"), gr.GetNode("n1")
+	require.Len(t, n0.Ports, 2)
+	require.Len(t, n1.Ports, 1)
+	assert.Equal(t, "input bus", n1.GetPort("in").Description)
+	assert.NotNil(t, n1.GetPort("in1"), "nested port")
+	assert.Nil(t, n1.GetPort("out0"))
+
+	// edges between different ports are distinct
+	e0 := gr.GetEdgeWithPorts("n0", "out0", "n1", "in0")
+	require.NotNil(t, e0)
+	assert.Equal(t, "e0", e0.ID)
+	assert.Equal(t, n0.GetPort("out0"), e0.SourcePort())
+	assert.Equal(t, n1.GetPort("in0"), e0.TargetPort())
+	assert.Equal(t, "e1", gr.GetEdgeWithPorts("n0", "out1", "n1", "in1").ID)
+	assert.Equal(t, "e2", gr.GetEdge("n0", "n1").ID)
+	assert.Nil(t, gr.GetEdge("n0", "n1").SourcePort())
+
+	// add edges
+	_, err = gr.AddEdgeWithPorts(n0, "out0", n1, "in0", nil, EdgeDirectionDefault, "")
+	assert.EqualError(t, err, "edge already added to the graph")
+	_, err = gr.AddEdgeWithPorts(n0, "out2", n1, "in0", nil, EdgeDirectionDefault, "")
+	assert.EqualError(t, err, "port: out2 not found at node: n0")
+	edge, err := gr.AddEdgeWithPorts(n0, "out1", n1, "in0", map[string]interface{}{"signal": "data"}, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, edge, gr.GetEdgeWithPorts("n0", "out1", "n1", "in0"))
+
+	// round trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<node id=\"n1\"><port name=\"in\"><desc>input bus</desc><port name=\"in0\"></port><port name=\"in1\"></port></port></node>")
+	assert.Contains(t, outBuf.String(), "<edge id=\"e3\" source=\"n0\" target=\"n1\" sourceport=\"out1\" targetport=\"in0\"><data key=\"d0\">data</data></edge>")
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	assert.Len(t, decoded.Graphs[0].Edges, 4)
+	assert.NotNil(t, decoded.Graphs[0].GetEdgeWithPorts("n0", "out1", "n1", "in0").TargetPort())
+}
+
+func TestGraph_GetEdge_Ports(t *testing.T) {
+	gml := NewGraphML("")
+	err := gml.DecodeString(`<graphml><graph id="g0" edgedefault="directed">` +
+		`<node id="n0"><port name="out"/></node><node id="n1"><port name="in"/></node>` +
+		`<edge id="e0" source="n0" target="n1" sourceport="out" targetport="in"/></graph></graphml>`)
+	require.NoError(t, err)
+	gr := gml.Graphs[0]
+	edge := gr.GetEdge("n0", "n1")
+	require.NotNil(t, edge)
+	assert.Equal(t, "e0", edge.ID)
+	assert.Nil(t, gr.GetEdge("n1", "n0"))
+}
+
+func TestNode_AddPort(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForPort, "pin", "", reflect.Int, 0)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("circuit", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	n0, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	out, err := n0.AddPort("out", map[string]interface{}{"pin": 1}, "output")
+	require.NoError(t, err)
+	bus, err := n1.AddPort("bus", nil, "")
+	require.NoError(t, err)
+	in0, err := bus.AddPort("in0", map[string]interface{}{"pin": 3}, "")
+	require.NoError(t, err)
+	_, err = n1.AddPort("in0", nil, "")
+	assert.EqualError(t, err, "port: in0 already exists at node: n1")
+	assert.Equal(t, in0, n1.GetPort("in0"))
+
+	attrs, err := out.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"pin": 1}, attrs)
+	attrs, err = bus.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"pin": 0}, attrs)
+
+	edge, err := gr.AddEdgeWithPorts(n0, "out", n1, "in0", nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, out, edge.SourcePort())
+	assert.Equal(t, in0, edge.TargetPort())
+
+	// round trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<key id=\"d0\" for=\"port\" attr.name=\"pin\" attr.type=\"int\">")
+	assert.Contains(t, outBuf.String(), "<port name=\"bus\"><port name=\"in0\"><data key=\"d0\">3</data></port></port>")
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	port := decoded.Graphs[0].GetEdgeWithPorts("n0", "out", "n1", "in0").TargetPort()
+	require.NotNil(t, port)
+	attrs, err = port.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"pin": 3}, attrs)
+
+	// remove key
+	err = decoded.RemoveKeyByName(KeyForPort, "pin")
+	require.NoError(t, err)
+	assert.Empty(t, port.Data)
+}
+
+func TestGraph_GenerateDescriptions(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("staff", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"name": "Alice", "dept": "eng", "level": 3}, "")
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"name": "Bob"}, "manual")
+	require.NoError(t, err)
+
+	err = gr.GenerateDescriptions("{name} ({id}) - {dept}, L{level}", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice (n0) - eng, L3", gr.Nodes[0].Description)
+	assert.Equal(t, "Bob (n1) - , L", gr.Nodes[1].Description, "string attribute is empty, level is missing")
+
+	err = gr.GenerateDescriptions("{name} L{level} {unclosed", true)
+	assert.EqualError(t, err, "node: n1 has no attribute: level")
+	assert.Equal(t, "Alice (n0) - eng, L3", gr.Nodes[0].Description, "intact on error")
+
+	err = gr.GenerateDescriptions("{name} {unclosed", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob {unclosed", gr.Nodes[1].Description)
+
+	// the string attribute declared but not set is missing unless its key has default value
+	err = gr.GenerateDescriptions("{name} - {dept}", true)
+	assert.EqualError(t, err, "node: n1 has no attribute: dept")
+	assert.Equal(t, "Bob {unclosed", gr.Nodes[1].Description, "intact on error")
+	_, err = gml.RegisterKey(KeyForNode, "title", "", reflect.String, "staff")
+	require.NoError(t, err)
+	err = gr.GenerateDescriptions("{name} - {title}", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob - staff", gr.Nodes[1].Description)
+}
+
+func TestGraphML_GetGraph(t *testing.T) {
+	gml := NewGraphML("")
+	g0, err := gml.AddGraph("first", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	g1, err := gml.AddGraph("second", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, g0, gml.GetGraph("g0"))
+	assert.Equal(t, g1, gml.GetGraph("g1"))
+	assert.Nil(t, gml.GetGraph("g2"))
+
+	// decoded
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.GetGraph("g1"))
+	assert.Equal(t, "second", decoded.GetGraph("g1").Description)
+	g2, err := decoded.AddGraph("third", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "g2", g2.ID)
+	assert.Equal(t, g2, decoded.GetGraph("g2"))
+}
+
+func TestGraphML_WalkNodes(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_nested.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err)
+	_, err = gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	nodes := make([]string, 0)
+	err = gml.WalkNodes(func(g *Graph, n *Node) error {
+		assert.Equal(t, g, n.graph)
+		nodes = append(nodes, n.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	expected := make([]string, 0)
+	for _, gr := range gml.allGraphs() {
+		for _, n := range gr.Nodes {
+			expected = append(expected, n.ID)
+		}
+	}
+	assert.Equal(t, expected, nodes)
+	assert.Contains(t, nodes, "n0::n1")
+
+	// stops at first error
+	count := 0
+	err = gml.WalkNodes(func(g *Graph, n *Node) error {
+		if count++; count == 2 {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 2, count)
+}
+
+func TestGraphML_WalkEdges(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_nested.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err)
+
+	expected := make([]*Edge, 0)
+	for _, gr := range gml.allGraphs() {
+		expected = append(expected, gr.Edges...)
+	}
+	require.NotEmpty(t, expected)
+	edges := make([]*Edge, 0)
+	err = gml.WalkEdges(func(g *Graph, e *Edge) error {
+		assert.Equal(t, g, e.graph)
+		edges = append(edges, e)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, edges)
+
+	err = gml.WalkEdges(func(g *Graph, e *Edge) error {
+		return errors.New(e.ID)
+	})
+	assert.EqualError(t, err, expected[0].ID)
+}
+
+func TestGraphML_ConcurrentRead(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_nested.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err)
+	expected := &bytes.Buffer{}
+	err = gml.Encode(expected, false)
+	require.NoError(t, err)
+
+	// run with -race flag to detect data races
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- readAll(gml, expected.String())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+// readAll calls reading methods of given GraphML and checks that it is encoded as expected
+func readAll(gml *GraphML, expected string) error {
+	if _, err := gml.GetAttributes(); err != nil {
+		return err
+	}
+	err := gml.WalkNodes(func(g *Graph, n *Node) error {
+		if _, err := n.GetAttributes(); err != nil {
+			return err
+		}
+		if _, err := n.AttributesWithKeys(); err != nil {
+			return err
+		}
+		if g.GetNode(n.ID) != n || gml.NodeByPath(n.Path()) != n {
+			return errors.New(fmt.Sprintf("node: %s not found", n.ID))
+		}
+		_ = n.Degree()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = gml.WalkEdges(func(g *Graph, e *Edge) error {
+		if _, err := e.GetAttributes(); err != nil {
+			return err
+		}
+		if g.GetEdge(e.Source, e.Target) == nil || e.SourceNode() == nil {
+			return errors.New(fmt.Sprintf("edge: %s not found", e.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, gr := range gml.Graphs {
+		_ = gr.ConnectedComponents()
+		if _, err = gr.HasCycle(); err != nil {
+			return err
+		}
+	}
+	if errs := gml.Validate(); len(errs) > 0 {
+		return errs[0]
+	}
+	outBuf := &bytes.Buffer{}
+	if err = gml.Encode(outBuf, false); err != nil {
+		return err
+	}
+	if outBuf.String() != expected {
+		return errors.New("encoded document differs")
+	}
+	return nil
+}
+
+func TestGraphML_EncodeToString(t *testing.T) {
+	gml := NewGraphML("test")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, map[string]interface{}{"weight": 1.5})
+	require.NoError(t, err)
+	_, err = gr.AddNode(nil, "")
+	require.NoError(t, err)
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, true)
+	require.NoError(t, err)
+	res, err := gml.EncodeToString(true)
+	require.NoError(t, err)
+	assert.Equal(t, outBuf.String(), res)
+
+	decoded := NewGraphML("")
+	err = decoded.DecodeString(res)
+	require.NoError(t, err)
+	require.Len(t, decoded.Graphs, 1)
+	assert.Equal(t, "test", decoded.Description)
+	attrs, err := decoded.Graphs[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.5}, attrs)
+
+	err = NewGraphML("").DecodeString("<graphml>")
+	assert.Error(t, err)
+}
+
+func TestGraphML_DecodeBytes(t *testing.T) {
+	data, err := ioutil.ReadFile("../data/test_graph.xml")
+	require.NoError(t, err)
+	gml := NewGraphML("")
+	err = gml.DecodeBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, "TestGraphML_Encode", gml.Description)
+	require.Len(t, gml.Graphs, 1)
+	assert.Len(t, gml.Graphs[0].Nodes, 2)
+	assert.Len(t, gml.Graphs[0].Edges, 1)
+
+	// UTF-16 document
+	outBuf := &bytes.Buffer{}
+	err = gml.EncodeWithOptions(outBuf, EncodeOptions{Charset: CharsetUTF16})
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.DecodeBytes(outBuf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, gml.Description, decoded.Description)
+
+	err = NewGraphML("").DecodeBytes(nil)
+	assert.Error(t, err)
+}
+
+func TestGraphML_RemoveGraph(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.AddGraph("first", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	g1, err := gml.AddGraph("second", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	n, err := g1.AddNode(nil, "")
+	require.NoError(t, err)
+	nested, err := n.AddGraph("nested", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	g3, err := gml.AddGraph("third", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+
+	// nested graph
+	err = gml.RemoveGraph(nested.ID)
+	require.NoError(t, err)
+	assert.Empty(t, n.Graphs)
+	assert.Nil(t, gml.GetGraph(nested.ID))
+	assert.Len(t, gml.Graphs, 3)
+
+	err = gml.RemoveGraph("g0")
+	require.NoError(t, err)
+	assert.Equal(t, []*Graph{g1, g3}, gml.Graphs)
+	assert.Nil(t, gml.GetGraph("g0"))
+
+	err = gml.RemoveGraph("g0")
+	assert.True(t, errors.Is(err, ErrGraphNotFound))
+	assert.EqualError(t, err, "graph not found: g0")
+
+	// the graph with nested graphs
+	_, err = n.AddGraph("nested", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	err = gml.RemoveGraph(g1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*Graph{g3}, gml.Graphs)
+	assert.Len(t, gml.allGraphs(), 1)
+	assert.Equal(t, g3, gml.GetGraph(g3.ID))
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	assert.Contains(t, outBuf.String(), "<graph id=\"g3\" edgedefault=\"directed\"><desc>third</desc></graph></graphml>")
+}
+
+func TestNode_GetAttribute(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+
+	// check consistency with attributes map
+	check := func(attrs map[string]interface{}, getAttribute func(name string) (interface{}, bool, error)) {
+		for name, expected := range attrs {
+			value, found, err := getAttribute(name)
+			require.NoError(t, err)
+			assert.True(t, found, name)
+			assert.Equal(t, expected, value, name)
+		}
+		_, found, err := getAttribute("unknown")
+		require.NoError(t, err)
+		assert.False(t, found)
+	}
+	attrs, err := gml.GetAttributes()
+	require.NoError(t, err)
+	check(attrs, gml.GetAttribute)
+	for _, gr := range gml.Graphs {
+		attrs, err = gr.GetAttributes()
+		require.NoError(t, err)
+		check(attrs, gr.GetAttribute)
+		for _, n := range gr.Nodes {
+			attrs, err = n.GetAttributes()
+			require.NoError(t, err)
+			check(attrs, n.GetAttribute)
+		}
+		for _, e := range gr.Edges {
+			attrs, err = e.GetAttributes()
+			require.NoError(t, err)
+			check(attrs, e.GetAttribute)
+		}
+	}
+
+	// default value
+	_, err = gml.RegisterKey(KeyForNode, "size", "", reflect.Int, 7)
+	require.NoError(t, err)
+	node := gml.Graphs[0].Nodes[0]
+	value, found, err := node.GetAttribute("size")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 7, value)
+
+	// invalid value
+	node.Data = append(node.Data, &Data{Key: gml.GetKey("size", KeyForNode).ID, Value: "seven"})
+	_, _, err = node.GetAttribute("size")
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package graphml
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// the JSON representations of GraphML elements
+type (
+	jsonGraphML struct {
+		Description string                 `json:"description,omitempty"`
+		Attributes  map[string]interface{} `json:"attributes,omitempty"`
+		Graphs      []*jsonGraph           `json:"graphs"`
+	}
+	jsonGraph struct {
+		ID          string                 `json:"id"`
+		EdgeDefault string                 `json:"edgedefault"`
+		Description string                 `json:"description,omitempty"`
+		Attributes  map[string]interface{} `json:"attributes,omitempty"`
+		Nodes       []*jsonNode            `json:"nodes"`
+		Edges       []*jsonEdge            `json:"edges"`
+	}
+	jsonNode struct {
+		ID          string                 `json:"id"`
+		Description string                 `json:"description,omitempty"`
+		Attributes  map[string]interface{} `json:"attributes,omitempty"`
+		Ports       []*jsonPort            `json:"ports,omitempty"`
+		Graphs      []*jsonGraph           `json:"graphs,omitempty"`
+	}
+	jsonPort struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Attributes  map[string]interface{} `json:"attributes,omitempty"`
+		Ports       []*jsonPort            `json:"ports,omitempty"`
+	}
+	jsonEdge struct {
+		ID          string                 `json:"id"`
+		Source      string                 `json:"source"`
+		Target      string                 `json:"target"`
+		Directed    bool                   `json:"directed"`
+		SourcePort  string                 `json:"sourceport,omitempty"`
+		TargetPort  string                 `json:"targetport,omitempty"`
+		Description string                 `json:"description,omitempty"`
+		Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	}
+)
+
+// MarshalJSON encodes graphs of this GraphML with their nodes and edges into JSON. The data of elements are written
+// as "attributes" objects holding the typed values of attributes as returned by GetAttributes. The NaN and infinite
+// float values, not supported by JSON, are written as "NaN", "INF" and "-INF" strings the same way as in GraphML.
+// The edges always hold the resolved "directed" flag.
+func (gml *GraphML) MarshalJSON() ([]byte, error) {
+	attrs, err := jsonAttributes(gml.GetAttributes())
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonGraphML{
+		Description: gml.Description,
+		Attributes:  attrs,
+	}
+	if res.Graphs, err = jsonGraphs(gml.Graphs); err != nil {
+		return nil, err
+	}
+	return json.Marshal(res)
+}
+
+func jsonGraphs(graphs []*Graph) ([]*jsonGraph, error) {
+	res := make([]*jsonGraph, len(graphs))
+	for i, gr := range graphs {
+		jg, err := gr.toJSON()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = jg
+	}
+	return res, nil
+}
+
+func (gr *Graph) toJSON() (*jsonGraph, error) {
+	attrs, err := jsonAttributes(gr.GetAttributes())
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonGraph{
+		ID:          gr.ID,
+		EdgeDefault: gr.EdgeDefault,
+		Description: gr.Description,
+		Attributes:  attrs,
+		Nodes:       make([]*jsonNode, len(gr.Nodes)),
+		Edges:       make([]*jsonEdge, len(gr.Edges)),
+	}
+	for i, n := range gr.Nodes {
+		if res.Nodes[i], err = n.toJSON(); err != nil {
+			return nil, err
+		}
+	}
+	for i, e := range gr.Edges {
+		if attrs, err = jsonAttributes(e.GetAttributes()); err != nil {
+			return nil, err
+		}
+		res.Edges[i] = &jsonEdge{
+			ID:          e.ID,
+			Source:      e.Source,
+			Target:      e.Target,
+			Directed:    e.directed(),
+			SourcePort:  e.SourcePortName,
+			TargetPort:  e.TargetPortName,
+			Description: e.Description,
+			Attributes:  attrs,
+		}
+	}
+	return res, nil
+}
+
+func (n *Node) toJSON() (*jsonNode, error) {
+	attrs, err := jsonAttributes(n.GetAttributes())
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonNode{
+		ID:          n.ID,
+		Description: n.Description,
+		Attributes:  attrs,
+	}
+	if res.Ports, err = jsonPorts(n.Ports); err != nil {
+		return nil, err
+	}
+	if len(n.Graphs) > 0 {
+		if res.Graphs, err = jsonGraphs(n.Graphs); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func jsonPorts(ports []*Port) ([]*jsonPort, error) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+	res := make([]*jsonPort, len(ports))
+	for i, p := range ports {
+		attrs, err := jsonAttributes(p.GetAttributes())
+		if err != nil {
+			return nil, err
+		}
+		res[i] = &jsonPort{
+			Name:        p.Name,
+			Description: p.Description,
+			Attributes:  attrs,
+		}
+		if res[i].Ports, err = jsonPorts(p.Ports); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// jsonAttributes prepares provided attributes for JSON encoding replacing NaN and infinite float values with their
+// XML Schema tokens (see specialFloatString)
+func jsonAttributes(attrs map[string]interface{}, err error) (map[string]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range attrs {
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			if token, ok := specialFloatString(v.Float()); ok {
+				attrs[name] = token
+			}
+		}
+	}
+	return attrs, nil
+}
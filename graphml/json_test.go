@@ -0,0 +1,63 @@
+package graphml
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestGraphML_MarshalJSON(t *testing.T) {
+	gml := NewGraphML("test")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, map[string]interface{}{"version": 2})
+	require.NoError(t, err)
+	n1, err := gr.AddNode(map[string]interface{}{"name": "first", "x": 1.5, "visible": true}, "node #1")
+	require.NoError(t, err)
+	_, err = n1.AddPort("north", nil, "")
+	require.NoError(t, err)
+	n2, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n1, n2, map[string]interface{}{"weight": 0.5}, EdgeDirectionUndirected, "")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gml)
+	require.NoError(t, err)
+
+	expected := `{
+  "description": "test",
+  "graphs": [{
+    "id": "g0", "edgedefault": "directed", "description": "test graph",
+    "attributes": {"version": 2},
+    "nodes": [
+      {"id": "n0", "description": "node #1", "attributes": {"name": "first", "x": 1.5, "visible": true},
+       "ports": [{"name": "north"}]},
+      {"id": "n1", "attributes": {"name": ""}}
+    ],
+    "edges": [
+      {"id": "e0", "source": "n0", "target": "n1", "directed": false, "attributes": {"weight": 0.5}}
+    ]
+  }]
+}`
+	assert.JSONEq(t, expected, string(data))
+
+	// check that values are typed
+	var res map[string]interface{}
+	err = json.Unmarshal(data, &res)
+	require.NoError(t, err)
+	node := res["graphs"].([]interface{})[0].(map[string]interface{})["nodes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 1.5, node["attributes"].(map[string]interface{})["x"])
+	assert.Equal(t, true, node["attributes"].(map[string]interface{})["visible"])
+}
+
+func TestGraphML_MarshalJSON_SpecialFloatValues(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddNode(map[string]interface{}{"a": math.NaN(), "b": math.Inf(1), "c": float32(math.Inf(-1)), "d": 1.5}, "")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gml)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"attributes":{"a":"NaN","b":"INF","c":"-INF","d":1.5}`)
+}
@@ -0,0 +1,79 @@
+package graphml
+
+import (
+	"time"
+)
+
+// The names of the GraphML attributes holding the timestamps
+const (
+	CreatedAttribute  = "created"
+	ModifiedAttribute = "modified"
+)
+
+// timeNow returns the current time, replaceable in tests
+var timeNow = time.Now
+
+// EnableTimestamps enables tracking of the creation and modification timestamps of this GraphML. The timestamps are
+// stored in Created and Modified fields as well as in the GraphML level data with "created" and "modified" names
+// in RFC 3339 format. The creation timestamp is set if not set yet and the modification timestamp is updated by
+// every mutating operation, i.e., AddGraph, AddNode, AddNodes, AddEdge, RemoveNode, SetAttribute, and
+// RemoveKey. The creation timestamp loaded from decoded document is kept.
+func (gml *GraphML) EnableTimestamps() error {
+	now := timeNow()
+	if gml.Created.IsZero() {
+		gml.Created = now
+	}
+	gml.Modified = now
+	if err := gml.storeTimestamps(); err != nil {
+		return err
+	}
+	gml.trackTimestamps = true
+	return nil
+}
+
+// touch updates the modification timestamp if tracking of timestamps enabled
+func (gml *GraphML) touch() {
+	if !gml.trackTimestamps {
+		return
+	}
+	gml.Modified = timeNow()
+	// the keys are validated when tracking enabled
+	_ = gml.storeTimestamps()
+}
+
+// storeTimestamps stores the timestamps into the data of GraphML
+func (gml *GraphML) storeTimestamps() (err error) {
+	if gml.Data, err = gml.setAttributeForData(gml.Data, KeyForGraphML, CreatedAttribute,
+		gml.Created.Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+	gml.Data, err = gml.setAttributeForData(gml.Data, KeyForGraphML, ModifiedAttribute,
+		gml.Modified.Format(time.RFC3339Nano))
+	return err
+}
+
+// loadTimestamps loads the timestamps from the data of GraphML if both found. The tracking of timestamps is not
+// enabled (see EnableTimestamps).
+func (gml *GraphML) loadTimestamps() {
+	attrs, err := gml.GetAttributes()
+	if err != nil {
+		return
+	}
+	created, ok := attrs[CreatedAttribute].(string)
+	if !ok {
+		return
+	}
+	modified, ok := attrs[ModifiedAttribute].(string)
+	if !ok {
+		return
+	}
+	createdTime, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return
+	}
+	modifiedTime, err := time.Parse(time.RFC3339Nano, modified)
+	if err != nil {
+		return
+	}
+	gml.Created, gml.Modified = createdTime, modifiedTime
+}
@@ -0,0 +1,78 @@
+package graphml
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGraphML_EnableTimestamps(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	timeNow = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	defer func() { timeNow = time.Now }()
+
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	assert.True(t, gml.Created.IsZero(), "not tracked by default")
+
+	err = gml.EnableTimestamps()
+	require.NoError(t, err)
+	created := time.Date(2020, 1, 2, 3, 5, 5, 0, time.UTC)
+	assert.Equal(t, created, gml.Created)
+	assert.Equal(t, created, gml.Modified)
+
+	// mutations
+	n0, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(time.Minute), gml.Modified)
+	n1, err := gr.AddNode(nil, "")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(n0, n1, nil, EdgeDirectionDefault, "")
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(3*time.Minute), gml.Modified)
+	err = n0.SetAttribute("color", "red")
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(4*time.Minute), gml.Modified)
+	err = gml.RemoveKeyByName(KeyForNode, "color")
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(5*time.Minute), gml.Modified)
+	assert.Equal(t, created, gml.Created)
+
+	attrs, err := gml.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"created":  "2020-01-02T03:05:05Z",
+		"modified": "2020-01-02T03:10:05Z",
+	}, attrs)
+
+	// round trip
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+	decoded := NewGraphML("")
+	err = decoded.Decode(outBuf)
+	require.NoError(t, err)
+	assert.True(t, created.Equal(decoded.Created))
+	assert.True(t, created.Add(5*time.Minute).Equal(decoded.Modified))
+	_, err = decoded.Graphs[0].AddNode(nil, "")
+	require.NoError(t, err)
+	assert.True(t, created.Add(5*time.Minute).Equal(decoded.Modified), "tracking not enabled by decoding")
+	attrs, err = decoded.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "2020-01-02T03:10:05Z", attrs["modified"])
+
+	// the tracking is opt-in and keeps the loaded creation timestamp
+	err = decoded.EnableTimestamps()
+	require.NoError(t, err)
+	assert.True(t, created.Equal(decoded.Created))
+	assert.Equal(t, created.Add(6*time.Minute), decoded.Modified)
+	_, err = decoded.Graphs[0].AddNode(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(7*time.Minute), decoded.Modified)
+}
@@ -0,0 +1,686 @@
+package graphml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// FilterSubgraph builds new graph which holds the nodes of this graph satisfying nodePred and the edges satisfying
+// edgePred which connect retained nodes. The attributes of the retained elements are copied. The nil predicate
+// means keeping of all elements. The new graph is created within its own GraphML which declares the same keys as
+// the parent of this graph, thus leaving the original document intact.
+func (gr *Graph) FilterSubgraph(nodePred func(*Node) bool, edgePred func(*Edge) bool) (*Graph, error) {
+	gml := gr.parent.cloneKeys()
+	return gr.copyInto(gml, nodePred, edgePred), nil
+}
+
+// cloneKeys creates new empty GraphML instance which holds copies of all keys registered with this one.
+// The IDs of keys are preserved, thus data of the elements can be copied to the new instance as is.
+func (gml *GraphML) cloneKeys() *GraphML {
+	res := NewGraphMLWithDefaultKeyType(gml.Description, gml.keyTypeDefault)
+	res.XmlNS = gml.XmlNS
+	res.XmlnsXsi = gml.XmlnsXsi
+	res.XsiSchemaLocation = gml.XsiSchemaLocation
+	for name, value := range gml.ExtraAttrs {
+		if res.ExtraAttrs == nil {
+			res.ExtraAttrs = make(map[string]string, len(gml.ExtraAttrs))
+		}
+		res.ExtraAttrs[name] = value
+	}
+	for keyType, fn := range gml.serializers {
+		res.SetSerializer(keyType, fn)
+	}
+	for keyType, fn := range gml.deserializers {
+		res.SetDeserializer(keyType, fn)
+	}
+	for _, k := range gml.Keys {
+		key := *k
+		res.addKey(&key)
+	}
+	return res
+}
+
+// copyInto creates copy of this graph within provided GraphML. Only the nodes accepted by nodePred and the edges
+// accepted by edgePred that connect accepted nodes are copied. The nil predicate accepts all elements. The graphs
+// nested into the copied nodes are copied entirely.
+func (gr *Graph) copyInto(gml *GraphML, nodePred func(*Node) bool, edgePred func(*Edge) bool) *Graph {
+	graph := gr.copyGraph(gml, nodePred, edgePred)
+	gml.Graphs = append(gml.Graphs, graph)
+	gml.linkGraph(graph)
+	return graph
+}
+
+// copyGraph creates copy of this graph with the graphs nested into its nodes within provided GraphML, which is
+// expected to declare the same keys. The nested graphs are linked with GraphML, but the copy itself is not.
+func (gr *Graph) copyGraph(gml *GraphML, nodePred func(*Node) bool, edgePred func(*Edge) bool) *Graph {
+	graph := &Graph{
+		ID:              gr.ID,
+		EdgeDefault:     gr.EdgeDefault,
+		Description:     gr.Description,
+		Nodes:           make([]*Node, 0),
+		Edges:           make([]*Edge, 0),
+		Data:            cloneData(gr.Data),
+		Locator:         gr.Locator.clone(),
+		AllowMultiEdges: gr.AllowMultiEdges,
+		parent:          gml,
+		nodesMap:        make(map[string]*Node),
+		edgesMap:        make(map[string]*Edge),
+		edgesById:       make(map[string]*Edge),
+		edgesDirection:  gr.edgesDirection,
+	}
+	for _, n := range gr.Nodes {
+		if nodePred != nil && !nodePred(n) {
+			continue
+		}
+		node := &Node{
+			ID:          n.ID,
+			Description: n.Description,
+			Data:        cloneData(n.Data),
+			Ports:       clonePorts(n.Ports),
+			Locator:     n.Locator.clone(),
+		}
+		for _, nested := range n.Graphs {
+			c := nested.copyGraph(gml, nil, nil)
+			c.parentNode = node
+			gml.linkGraph(c)
+			node.Graphs = append(node.Graphs, c)
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		graph.linkNode(node)
+	}
+	for _, e := range gr.Edges {
+		if graph.GetNode(e.Source) == nil || graph.GetNode(e.Target) == nil {
+			continue
+		}
+		if edgePred != nil && !edgePred(e) {
+			continue
+		}
+		edge := &Edge{
+			ID:             e.ID,
+			Source:         e.Source,
+			Target:         e.Target,
+			Directed:       e.Directed,
+			SourcePortName: e.SourcePortName,
+			TargetPortName: e.TargetPortName,
+			Description:    e.Description,
+			Data:           cloneData(e.Data),
+		}
+		graph.Edges = append(graph.Edges, edge)
+		graph.linkEdge(edge)
+	}
+	return graph
+}
+
+// importGraph creates copy of the other graph with the graphs nested into its nodes, reconciling keys of the other
+// GraphML with keys of this GraphML (see mergeData). The IDs of copied graphs are prefixed with idPrefix. If such ID
+// is already taken by the graph of this GraphML or by the graph listed in taken, then either new ID is generated if
+// renameTaken is set, or error returned. The IDs of copied graphs are added to taken. The copies are not linked with
+// this GraphML (see linkGraphs).
+func (gml *GraphML) importGraph(other *Graph, idPrefix string, taken map[string]bool, renameTaken bool) (*Graph, error) {
+	id := idPrefix + other.ID
+	for count := len(gml.graphsById) + len(taken); gml.GetGraph(id) != nil || taken[id]; count++ {
+		if !renameTaken {
+			return nil, errors.New(fmt.Sprintf("graph with ID: %s already exists", id))
+		}
+		id = fmt.Sprintf("g%d", count)
+	}
+	taken[id] = true
+	graph := &Graph{
+		ID:              id,
+		EdgeDefault:     other.EdgeDefault,
+		Description:     other.Description,
+		Nodes:           make([]*Node, 0, len(other.Nodes)),
+		Edges:           make([]*Edge, 0, len(other.Edges)),
+		Locator:         other.Locator.clone(),
+		AllowMultiEdges: other.AllowMultiEdges,
+		nodesMap:        make(map[string]*Node),
+		edgesMap:        make(map[string]*Edge),
+		edgesById:       make(map[string]*Edge),
+		edgesDirection:  other.edgesDirection,
+	}
+	var err error
+	if graph.Data, err = gml.mergeData(nil, other.Data, other.parent); err != nil {
+		return nil, err
+	}
+	for _, n := range other.Nodes {
+		node := &Node{
+			ID:          n.ID,
+			Description: n.Description,
+			Ports:       clonePorts(n.Ports),
+			Locator:     n.Locator.clone(),
+		}
+		if node.Data, err = gml.mergeData(nil, n.Data, other.parent); err != nil {
+			return nil, err
+		}
+		if node.Graphs, err = gml.importGraphs(n.Graphs, idPrefix, taken, renameTaken); err != nil {
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		graph.linkNode(node)
+	}
+	for _, e := range other.Edges {
+		edge := &Edge{
+			ID:             e.ID,
+			Source:         e.Source,
+			Target:         e.Target,
+			Directed:       e.Directed,
+			SourcePortName: e.SourcePortName,
+			TargetPortName: e.TargetPortName,
+			Description:    e.Description,
+		}
+		if edge.Data, err = gml.mergeData(nil, e.Data, other.parent); err != nil {
+			return nil, err
+		}
+		graph.Edges = append(graph.Edges, edge)
+		graph.linkEdge(edge)
+	}
+	return graph, nil
+}
+
+// importGraphs imports provided graphs from the other GraphML (see importGraph)
+func (gml *GraphML) importGraphs(graphs []*Graph, idPrefix string, taken map[string]bool, renameTaken bool) ([]*Graph, error) {
+	if len(graphs) == 0 {
+		return nil, nil
+	}
+	res := make([]*Graph, len(graphs))
+	for i, other := range graphs {
+		graph, err := gml.importGraph(other, idPrefix, taken, renameTaken)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = graph
+	}
+	return res, nil
+}
+
+// linkGraphs links the graphs nested into provided node and their nested graphs with this GraphML
+func (gml *GraphML) linkGraphs(node *Node) {
+	for _, nested := range node.Graphs {
+		nested.parentNode = node
+		gml.linkGraph(nested)
+		for _, n := range nested.Nodes {
+			gml.linkGraphs(n)
+		}
+	}
+}
+
+// cloneData creates deep copy of the provided data list
+func cloneData(data []*Data) []*Data {
+	res := make([]*Data, len(data))
+	for i, d := range data {
+		c := *d
+		res[i] = &c
+	}
+	return res
+}
+
+// clonePorts creates deep copy of the provided ports list
+func clonePorts(ports []*Port) []*Port {
+	if ports == nil {
+		return nil
+	}
+	res := make([]*Port, len(ports))
+	for i, p := range ports {
+		res[i] = &Port{
+			Name:        p.Name,
+			Description: p.Description,
+			Data:        cloneData(p.Data),
+			Ports:       clonePorts(p.Ports),
+		}
+	}
+	return res
+}
+
+// Union creates new graph which holds all nodes and edges of both provided graphs. The nodes are identified by their
+// IDs and the edges by IDs of the connected nodes. The attributes of the elements present in both graphs are merged
+// with values of the graph a taking precedence over values of the graph b. The new graph is created within its own
+// GraphML which declares keys of both parent documents reconciled by name and target. Returns error if keys with the
+// same name and target have different types or default values.
+func Union(a, b *Graph) (*Graph, error) {
+	gml := a.parent.cloneKeys()
+	graph := a.copyInto(gml, nil, nil)
+	if err := graph.mergeFrom(b, true); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// Intersection creates new graph which holds only nodes and edges present in both provided graphs. The nodes are
+// identified by their IDs and the edges by IDs of the connected nodes. The attributes of the elements are merged with
+// values of the graph a taking precedence over values of the graph b. The new graph is created within its own GraphML
+// which declares keys of both parent documents reconciled by name and target. Returns error if keys with the same name
+// and target have different types or default values.
+func Intersection(a, b *Graph) (*Graph, error) {
+	gml := a.parent.cloneKeys()
+	nodePred := func(n *Node) bool {
+		return b.GetNode(n.ID) != nil
+	}
+	edgePred := func(e *Edge) bool {
+		return b.GetEdge(e.Source, e.Target) != nil
+	}
+	graph := a.copyInto(gml, nodePred, edgePred)
+	if err := graph.mergeFrom(b, false); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// Merge copies all nodes and edges of the other graph into this graph. The IDs of copied nodes and edges are prefixed
+// by the given prefix to avoid collisions, and the edges are connected to the copied nodes accordingly. The keys
+// referenced by the data of copied elements are reconciled with keys of this graph's GraphML by name and target.
+// The attributes of the other graph itself are not copied. Returns error if prefixed IDs collide with IDs of this
+// graph's elements, or if keys with the same name and target have different types or default values. This graph
+// is left intact in case of error, except for the keys already imported. The graphs nested into the nodes of the
+// other graph are copied with IDs prefixed the same way, and it is an error if such ID is already taken.
+func (gr *Graph) Merge(other *Graph, idPrefix string) (err error) {
+	nodes := make([]*Node, len(other.Nodes))
+	taken := make(map[string]bool)
+	for i, n := range other.Nodes {
+		nodes[i] = &Node{
+			ID:          idPrefix + n.ID,
+			Description: n.Description,
+			Ports:       clonePorts(n.Ports),
+			Locator:     n.Locator.clone(),
+		}
+		if gr.GetNode(nodes[i].ID) != nil {
+			return errors.New(fmt.Sprintf("node with ID: %s already exists", nodes[i].ID))
+		}
+		if nodes[i].Data, err = gr.parent.mergeData(nil, n.Data, other.parent); err != nil {
+			return err
+		}
+		if nodes[i].Graphs, err = gr.parent.importGraphs(n.Graphs, idPrefix, taken, false); err != nil {
+			return err
+		}
+	}
+	edges := make([]*Edge, len(other.Edges))
+	for i, e := range other.Edges {
+		edges[i] = &Edge{
+			ID:             idPrefix + e.ID,
+			Source:         idPrefix + e.Source,
+			Target:         idPrefix + e.Target,
+			Directed:       e.Directed,
+			SourcePortName: e.SourcePortName,
+			TargetPortName: e.TargetPortName,
+			Description:    e.Description,
+		}
+		if gr.GetEdgeByID(edges[i].ID) != nil {
+			return errors.New(fmt.Sprintf("edge with ID: %s already exists", edges[i].ID))
+		}
+		if e.Directed == "" && other.edgesDirection != gr.edgesDirection {
+			// keep direction of the edge when default directions of graphs differ
+			edges[i].Directed = strconv.FormatBool(e.directed())
+		}
+		if edges[i].Data, err = gr.parent.mergeData(nil, e.Data, other.parent); err != nil {
+			return err
+		}
+	}
+	for _, n := range nodes {
+		gr.Nodes = append(gr.Nodes, n)
+		gr.linkNode(n)
+		gr.parent.linkGraphs(n)
+	}
+	for _, e := range edges {
+		gr.Edges = append(gr.Edges, e)
+		gr.linkEdge(e)
+	}
+	gr.parent.touch()
+	return nil
+}
+
+// mergeFrom merges attributes of the other graph and its elements present in this graph into this graph. The existing
+// attributes of this graph take precedence. If addMissing is set then the nodes and edges of the other graph absent
+// in this graph are added to it.
+func (gr *Graph) mergeFrom(other *Graph, addMissing bool) (err error) {
+	if gr.Data, err = gr.parent.mergeData(gr.Data, other.Data, other.parent); err != nil {
+		return err
+	}
+	for _, n := range other.Nodes {
+		node := gr.GetNode(n.ID)
+		if node == nil {
+			if !addMissing {
+				continue
+			}
+			node = &Node{
+				ID:          n.ID,
+				Description: n.Description,
+				Ports:       clonePorts(n.Ports),
+				Locator:     n.Locator.clone(),
+			}
+			if node.Graphs, err = gr.parent.importGraphs(n.Graphs, "", make(map[string]bool), true); err != nil {
+				return err
+			}
+			gr.Nodes = append(gr.Nodes, node)
+			gr.linkNode(node)
+			gr.parent.linkGraphs(node)
+		}
+		if node.Data, err = gr.parent.mergeData(node.Data, n.Data, other.parent); err != nil {
+			return err
+		}
+	}
+	for _, e := range other.Edges {
+		edge := gr.GetEdge(e.Source, e.Target)
+		if edge == nil {
+			if !addMissing || gr.GetNode(e.Source) == nil || gr.GetNode(e.Target) == nil {
+				continue
+			}
+			edge = &Edge{
+				ID:             e.ID,
+				Source:         e.Source,
+				Target:         e.Target,
+				Directed:       e.Directed,
+				SourcePortName: e.SourcePortName,
+				TargetPortName: e.TargetPortName,
+				Description:    e.Description,
+			}
+			if gr.GetEdgeByID(edge.ID) != nil {
+				edge.ID = gr.nextEdgeId()
+			}
+			gr.Edges = append(gr.Edges, edge)
+			gr.linkEdge(edge)
+		}
+		if edge.Data, err = gr.parent.mergeData(edge.Data, e.Data, other.parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeData appends copies of the data from the other GraphML to the given data list if it has no data for the same
+// attribute. The keys of the other GraphML are reconciled with keys of this GraphML by name and target.
+func (gml *GraphML) mergeData(data, otherData []*Data, other *GraphML) ([]*Data, error) {
+	for _, d := range otherData {
+		otherKey, ok := other.keysById[d.Key]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("failed to find attribute name/type by id: %s", d.Key))
+		}
+		key, err := gml.importKey(otherKey)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, existing := range data {
+			if existing.Key == key.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c := *d
+			c.Key = key.ID
+			data = append(data, &c)
+		}
+	}
+	return data, nil
+}
+
+// importKey looks for the key with the same name and target as provided key from other GraphML. If not found, the copy
+// of the provided key is registered, keeping its ID if it is not taken. The keys differing only in description are
+// merged keeping the longer description. Returns error if found key has different type or default value.
+func (gml *GraphML) importKey(other *Key) (*Key, error) {
+	if key, ok := gml.keysByIdentifier[keyIdentifier(other.Name, other.Target)]; ok {
+		if key.KeyType != other.KeyType {
+			return nil, errors.New(fmt.Sprintf("key: %s has conflicting types: %s, %s", key.Name, key.KeyType, other.KeyType))
+		}
+		if key.DefaultValue != other.DefaultValue {
+			return nil, errors.New(fmt.Sprintf("key: %s has conflicting default values: %s, %s",
+				key.Name, key.DefaultValue, other.DefaultValue))
+		}
+		if len(other.Description) > len(key.Description) {
+			key.Description = other.Description
+		}
+		return key, nil
+	}
+	key := *other
+	if _, taken := gml.keysById[key.ID]; taken {
+		key.ID = gml.nextKeyId()
+	}
+	gml.addKey(&key)
+	return &key, nil
+}
+
+// AggFunc The function to aggregate weights of collapsed parallel edges
+type AggFunc int
+
+const (
+	// AggSum the sum of weights
+	AggSum AggFunc = iota
+	// AggCount the number of edges
+	AggCount
+	// AggMean the mean of weights
+	AggMean
+	// AggMax the maximal weight
+	AggMax
+)
+
+// CollapseParallelEdges builds new simple graph where all parallel edges of this graph are merged into one edge
+// with aggregated weight stored in the attribute with given name. The directed edges are parallel if they have the same
+// source and target, the undirected edges are parallel if they connect the same nodes. The merged edge keeps ID,
+// description, and other attributes of the first edge in the group. The weights of the edges are read from the
+// attribute with given name, unless AggCount is used. The aggregated weight is stored into the same attribute, thus
+// AggMean requires the weight key of float or double type, otherwise error is returned. The new graph is created
+// within its own GraphML which declares the same keys as the parent of this graph.
+func (gr *Graph) CollapseParallelEdges(weightKey string, agg AggFunc) (*Graph, error) {
+	if agg < AggSum || agg > AggMax {
+		return nil, errors.New(fmt.Sprintf("unsupported aggregation function: %d", agg))
+	}
+	if key := gr.parent.GetKey(weightKey, KeyForEdge); agg == AggMean && key != nil &&
+		key.KeyType != FloatType && key.KeyType != DoubleType {
+		return nil, errors.New(fmt.Sprintf("mean of weights requires key: %s of float or double type, found: %s",
+			weightKey, key.KeyType))
+	}
+	gml := gr.parent.cloneKeys()
+	graph := gr.copyInto(gml, nil, func(*Edge) bool { return false })
+
+	// group parallel edges preserving order of the first occurrence
+	groups := make(map[string][]*Edge)
+	order := make([]string, 0)
+	for _, e := range gr.Edges {
+		var id string
+		if e.directed() {
+			id = "directed:" + edgeIdentifier(e.Source, e.Target)
+		} else if e.Source < e.Target {
+			id = "undirected:" + edgeIdentifier(e.Source, e.Target)
+		} else {
+			id = "undirected:" + edgeIdentifier(e.Target, e.Source)
+		}
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], e)
+	}
+
+	for _, id := range order {
+		edges := groups[id]
+		first := edges[0]
+		edge := &Edge{
+			ID:             first.ID,
+			Source:         first.Source,
+			Target:         first.Target,
+			Directed:       first.Directed,
+			SourcePortName: first.SourcePortName,
+			TargetPortName: first.TargetPortName,
+			Description:    first.Description,
+			Data:           cloneData(first.Data),
+		}
+		graph.Edges = append(graph.Edges, edge)
+		graph.linkEdge(edge)
+
+		if agg == AggCount {
+			if err := edge.SetAttribute(weightKey, len(edges)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var weight float64
+		for i, e := range edges {
+			attrs, err := e.GetAttributes()
+			if err != nil {
+				return nil, err
+			}
+			value, ok := attrs[weightKey]
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("edge: %s has no weight attribute: %s", e.ID, weightKey))
+			}
+			w, err := floatValue(value)
+			if err != nil {
+				return nil, err
+			}
+			if agg == AggMax && i > 0 {
+				weight = math.Max(weight, w)
+			} else if agg == AggMax {
+				weight = w
+			} else {
+				weight += w
+			}
+		}
+		if agg == AggMean {
+			weight /= float64(len(edges))
+		}
+		if err := edge.SetAttribute(weightKey, weight); err != nil {
+			return nil, err
+		}
+	}
+	return graph, nil
+}
+
+// TransitiveReduction builds new graph which holds all nodes of this directed acyclic graph and only the edges not
+// implied by longer paths, i.e., the edge from u to v is removed if v is reachable from u by other path. Of the parallel
+// edges only the first one is retained. The reachability between nodes is the same as in this graph. The new graph is
+// created within its own GraphML which declares the same keys as the parent of this graph. Returns error if graph has
+// undirected edges or cycles.
+func (gr *Graph) TransitiveReduction() (*Graph, error) {
+	for _, e := range gr.Edges {
+		if !e.directed() {
+			return nil, errors.New(fmt.Sprintf("transitive reduction requires directed edges, found undirected: %s", e.ID))
+		}
+	}
+	if cycle, err := gr.FindCycleEdges(); err != nil {
+		return nil, err
+	} else if cycle != nil {
+		return nil, errors.New(fmt.Sprintf("transitive reduction requires acyclic graph, found cycle at edge: %s", cycle[0].ID))
+	}
+
+	successors := make(map[string][]string)
+	for _, e := range gr.Edges {
+		successors[e.Source] = append(successors[e.Source], e.Target)
+	}
+	// the sets of nodes reachable from each node
+	reachable := make(map[string]map[string]bool)
+	var reach func(id string) map[string]bool
+	reach = func(id string) map[string]bool {
+		if res, ok := reachable[id]; ok {
+			return res
+		}
+		res := make(map[string]bool)
+		for _, s := range successors[id] {
+			res[s] = true
+			for r := range reach(s) {
+				res[r] = true
+			}
+		}
+		reachable[id] = res
+		return res
+	}
+
+	retained := make(map[*Edge]bool)
+	seen := make(map[string]bool)
+	for _, e := range gr.Edges {
+		id := edgeIdentifier(e.Source, e.Target)
+		if seen[id] {
+			// parallel edge
+			continue
+		}
+		seen[id] = true
+		redundant := false
+		for _, s := range successors[e.Source] {
+			if s != e.Target && reach(s)[e.Target] {
+				redundant = true
+				break
+			}
+		}
+		retained[e] = !redundant
+	}
+	gml := gr.parent.cloneKeys()
+	return gr.copyInto(gml, nil, func(e *Edge) bool { return retained[e] }), nil
+}
+
+// Reversed builds new graph with the same nodes as this graph and all edges reversed, i.e., with source and target
+// swapped. The IDs, descriptions, and attributes of all elements are preserved. The new graph is created within its
+// own GraphML which declares the same keys as the parent of this graph.
+func (gr *Graph) Reversed() (*Graph, error) {
+	gml := gr.parent.cloneKeys()
+	graph := gr.copyInto(gml, nil, func(*Edge) bool { return false })
+	for _, e := range gr.Edges {
+		edge := &Edge{
+			ID:             e.ID,
+			Source:         e.Target,
+			Target:         e.Source,
+			Directed:       e.Directed,
+			SourcePortName: e.TargetPortName,
+			TargetPortName: e.SourcePortName,
+			Description:    e.Description,
+			Data:           cloneData(e.Data),
+		}
+		graph.Edges = append(graph.Edges, edge)
+		graph.linkEdge(edge)
+	}
+	return graph, nil
+}
+
+// Transpose builds new graph with all edges reversed. It is an alias of Reversed named after the transposition of
+// the adjacency matrix.
+func (gr *Graph) Transpose() (*Graph, error) {
+	return gr.Reversed()
+}
+
+// GraphFromAdjacencyMap creates new GraphML document holding the graph built from the provided adjacency map, where
+// each key is the ID of the node and the value is the list of IDs of its adjacent nodes. The node is created for each
+// key and each referenced ID, in sorted order of IDs. The edges are added in sorted order of their source IDs. The
+// repeated adjacency is ignored, as well as the reverse adjacency for the undirected graph.
+func GraphFromAdjacencyMap(adj map[string][]string, directed bool) (*GraphML, error) {
+	edgeDefault := EdgeDirectionUndirected
+	if directed {
+		edgeDefault = EdgeDirectionDirected
+	}
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("", edgeDefault, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	sources := make([]string, 0, len(adj))
+	for source, targets := range adj {
+		sources = append(sources, source)
+		ids[source] = true
+		for _, target := range targets {
+			ids[target] = true
+		}
+	}
+	sort.Strings(sources)
+	nodeIDs := make([]string, 0, len(ids))
+	for id := range ids {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+	for _, id := range nodeIDs {
+		if _, err = gr.addNode(id, nil, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, source := range sources {
+		for _, target := range adj[source] {
+			if gr.GetEdge(source, target) != nil {
+				continue
+			}
+			if _, err = gr.AddEdgeByID(source, target, nil, EdgeDirectionDefault, "", false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return gml, nil
+}
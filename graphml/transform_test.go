@@ -0,0 +1,512 @@
+package graphml
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGraph_FilterSubgraph(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("test graph", EdgeDirectionDirected, map[string]interface{}{"name": "staff"})
+	require.NoError(t, err)
+
+	eng1, err := gr.AddNode(map[string]interface{}{"department": "eng"}, "#1")
+	require.NoError(t, err)
+	eng2, err := gr.AddNode(map[string]interface{}{"department": "eng"}, "#2")
+	require.NoError(t, err)
+	sales, err := gr.AddNode(map[string]interface{}{"department": "sales"}, "#3")
+	require.NoError(t, err)
+	eng3, err := gr.AddNode(map[string]interface{}{"department": "eng"}, "#4")
+	require.NoError(t, err)
+
+	_, err = gr.AddEdge(eng1, eng2, map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "e1")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(eng2, sales, map[string]interface{}{"weight": 2.0}, EdgeDirectionDefault, "e2")
+	require.NoError(t, err)
+	_, err = gr.AddEdge(eng2, eng3, map[string]interface{}{"weight": 3.0}, EdgeDirectionDefault, "e3")
+	require.NoError(t, err)
+
+	nodePred := func(n *Node) bool {
+		attrs, err := n.GetAttributes()
+		return err == nil && attrs["department"] == "eng"
+	}
+	edgePred := func(e *Edge) bool {
+		attrs, err := e.GetAttributes()
+		return err == nil && attrs["weight"].(float64) < 3
+	}
+	sub, err := gr.FilterSubgraph(nodePred, edgePred)
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+
+	require.Len(t, sub.Nodes, 3)
+	assert.Equal(t, eng1.ID, sub.Nodes[0].ID)
+	assert.Equal(t, eng2.ID, sub.Nodes[1].ID)
+	assert.Equal(t, eng3.ID, sub.Nodes[2].ID)
+	require.Len(t, sub.Edges, 1)
+	assert.Equal(t, "e1", sub.Edges[0].Description)
+	assert.NotNil(t, sub.GetEdge(eng1.ID, eng2.ID))
+	assert.NotNil(t, sub.Edges[0].SourceNode())
+
+	// check copied attributes
+	attrs, err := sub.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "staff"}, attrs)
+	attrs, err = sub.Nodes[2].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"department": "eng"}, attrs)
+	attrs, err = sub.Edges[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.0}, attrs)
+
+	// check that attributes are copied and original graph is intact
+	err = sub.Nodes[0].SetAttribute("department", "ops")
+	require.NoError(t, err)
+	attrs, err = eng1.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "eng", attrs["department"])
+	assert.Len(t, gml.Graphs, 1)
+
+	// check nil predicates
+	sub, err = gr.FilterSubgraph(nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, sub.Nodes, 4)
+	assert.Len(t, sub.Edges, 3)
+}
+
+func TestUnion(t *testing.T) {
+	gmlA := NewGraphML("")
+	a, err := gmlA.AddGraph("a", EdgeDirectionDirected, map[string]interface{}{"name": "a"})
+	require.NoError(t, err)
+	_, err = a.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "a edge", true)
+	require.NoError(t, err)
+	require.NoError(t, a.GetNode("n0").SetAttribute("color", "red"))
+
+	gmlB := NewGraphML("")
+	_, err = gmlB.RegisterKey(KeyForNode, "size", "", reflect.Int, nil)
+	require.NoError(t, err)
+	b, err := gmlB.AddGraph("b", EdgeDirectionDirected, map[string]interface{}{"name": "b", "version": 2})
+	require.NoError(t, err)
+	_, err = b.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": 2.0, "label": "b"}, EdgeDirectionDefault, "b edge", true)
+	require.NoError(t, err)
+	_, err = b.AddEdgeByID("n1", "n2", nil, EdgeDirectionDefault, "b edge 2", true)
+	require.NoError(t, err)
+	require.NoError(t, b.GetNode("n0").SetAttribute("color", "blue"))
+	require.NoError(t, b.GetNode("n0").SetAttribute("size", 10))
+
+	u, err := Union(a, b)
+	require.NoError(t, err)
+	assert.Len(t, u.Nodes, 3)
+	assert.Len(t, u.Edges, 2)
+	assert.Equal(t, "a edge", u.GetEdge("n0", "n1").Description)
+	assert.Equal(t, "b edge 2", u.GetEdge("n1", "n2").Description)
+	assert.Equal(t, "e1", u.GetEdge("n1", "n2").ID)
+
+	attrs, err := u.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "a", "version": 2}, attrs)
+	attrs, err = u.GetNode("n0").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red", "size": 10}, attrs)
+	attrs, err = u.GetEdge("n0", "n1").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.0, "label": "b"}, attrs)
+
+	// the source graphs are intact
+	assert.Len(t, a.Nodes, 2)
+	assert.Len(t, gmlA.Keys, 3)
+
+	// keys conflict
+	gmlC := NewGraphML("")
+	c, err := gmlC.AddGraph("c", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = c.AddNode(map[string]interface{}{"color": 1}, "")
+	require.NoError(t, err)
+	_, err = Union(a, c)
+	assert.Error(t, err)
+}
+
+func TestIntersection(t *testing.T) {
+	gmlA := NewGraphML("")
+	a, err := gmlA.AddGraph("a", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	_, err = a.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = a.AddEdgeByID("n1", "n2", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = a.AddEdgeByID("n2", "n3", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+
+	gmlB := NewGraphML("")
+	b, err := gmlB.AddGraph("b", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	// reversed undirected edge is the same edge
+	_, err = b.AddEdgeByID("n1", "n0", map[string]interface{}{"label": "b"}, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = b.AddEdgeByID("n2", "n4", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = b.AddNode(nil, "")
+	require.NoError(t, err)
+	b.GetNode("n2").Description = "b node"
+
+	i, err := Intersection(a, b)
+	require.NoError(t, err)
+	require.Len(t, i.Nodes, 3)
+	assert.NotNil(t, i.GetNode("n0"))
+	assert.NotNil(t, i.GetNode("n1"))
+	assert.NotNil(t, i.GetNode("n2"))
+	require.Len(t, i.Edges, 1)
+	attrs, err := i.Edges[0].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 1.0, "label": "b"}, attrs)
+}
+
+func TestGraph_CollapseParallelEdges(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_parallel_edges.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	gr := gml.Graphs[0]
+
+	testCases := map[AggFunc][]interface{}{
+		AggSum:   {9.0, 4.0, 3.0},
+		AggCount: {3, 1, 2},
+		AggMean:  {3.0, 4.0, 1.5},
+		AggMax:   {5.0, 4.0, 2.0},
+	}
+	for agg, weights := range testCases {
+		weightKey := "weight"
+		if agg == AggCount {
+			weightKey = "count"
+		}
+		collapsed, err := gr.CollapseParallelEdges(weightKey, agg)
+		require.NoError(t, err, "failed to collapse with: %d", agg)
+		assert.Len(t, collapsed.Nodes, 3)
+		require.Len(t, collapsed.Edges, 3)
+		for i, e := range collapsed.Edges {
+			attrs, err := e.GetAttributes()
+			require.NoError(t, err)
+			assert.Equal(t, weights[i], attrs[weightKey], "wrong weight of edge: %s, aggregation: %d", e.ID, agg)
+		}
+		// the first edge of the group is kept
+		assert.Equal(t, "e0", collapsed.Edges[0].ID)
+		assert.Equal(t, "first route", collapsed.Edges[0].Description)
+		attrs, err := collapsed.Edges[0].GetAttributes()
+		require.NoError(t, err)
+		assert.Equal(t, "first", attrs["label"])
+		assert.Equal(t, "e2", collapsed.Edges[1].ID)
+		assert.Equal(t, "e4", collapsed.Edges[2].ID)
+	}
+	// the source graph is intact
+	assert.Len(t, gr.Edges, 6)
+
+	_, err = gr.CollapseParallelEdges("label", AggSum)
+	assert.Error(t, err, "non numeric weight")
+	_, err = gr.CollapseParallelEdges("weight", AggFunc(42))
+	assert.Error(t, err, "unsupported aggregation")
+}
+
+func TestGraph_CollapseParallelEdges_IntWeights(t *testing.T) {
+	gml := NewGraphML("")
+	_, err := gml.RegisterKey(KeyForEdge, "weight", "", reflect.Int, nil)
+	require.NoError(t, err)
+	gr, err := gml.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	gr.AllowMultiEdges = true
+	for _, w := range []int{1, 2} {
+		_, err = gr.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": w}, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+
+	for agg, expected := range map[AggFunc]int{AggSum: 3, AggMax: 2, AggCount: 2} {
+		collapsed, err := gr.CollapseParallelEdges("weight", agg)
+		require.NoError(t, err, "failed to collapse with: %d", agg)
+		require.Len(t, collapsed.Edges, 1)
+		weight, _, err := collapsed.Edges[0].GetAttribute("weight")
+		require.NoError(t, err)
+		assert.Equal(t, expected, weight, "aggregation: %d", agg)
+	}
+	_, err = gr.CollapseParallelEdges("weight", AggMean)
+	assert.EqualError(t, err, "mean of weights requires key: weight of float or double type, found: int")
+}
+
+func TestGraph_TransitiveReduction(t *testing.T) {
+	gml := NewGraphML("")
+	gr, err := gml.AddGraph("dependencies", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	pairs := [][2]string{{"a", "b"}, {"b", "c"}, {"a", "c"}, {"c", "d"}, {"a", "d"}, {"b", "d"}, {"e", "d"}}
+	for _, pair := range pairs {
+		_, err = gr.AddEdgeByID(pair[0], pair[1], map[string]interface{}{"label": pair[0] + pair[1]}, EdgeDirectionDefault, "", true)
+		require.NoError(t, err)
+	}
+
+	reduced, err := gr.TransitiveReduction()
+	require.NoError(t, err)
+	assert.Len(t, reduced.Nodes, 5)
+	ids := make([]string, len(reduced.Edges))
+	for i, e := range reduced.Edges {
+		ids[i] = e.ID
+	}
+	assert.Equal(t, []string{"e0", "e1", "e3", "e6"}, ids)
+	attrs, err := reduced.Edges[1].GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, "bc", attrs["label"])
+	// the source graph is intact
+	assert.Len(t, gr.Edges, len(pairs))
+
+	// cycle
+	_, err = gr.AddEdgeByID("d", "a", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.TransitiveReduction()
+	assert.Error(t, err)
+
+	// undirected
+	gr, err = gml.AddGraph("undirected", EdgeDirectionUndirected, nil)
+	require.NoError(t, err)
+	_, err = gr.AddEdgeByID("a", "b", nil, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	_, err = gr.TransitiveReduction()
+	assert.Error(t, err)
+}
+
+func TestGraph_Transpose(t *testing.T) {
+	graphFile, err := os.Open("../data/test_graph_parallel_edges.xml")
+	require.NoError(t, err, "failed to open file")
+	gml := NewGraphML("")
+	err = gml.Decode(graphFile)
+	require.NoError(t, err, "failed to decode")
+	gr := gml.Graphs[0]
+
+	for _, transpose := range []func() (*Graph, error){gr.Transpose, gr.Reversed} {
+		transposed, err := transpose()
+		require.NoError(t, err)
+		require.Len(t, transposed.Nodes, len(gr.Nodes))
+		require.Len(t, transposed.Edges, len(gr.Edges))
+		for i, e := range gr.Edges {
+			reversed := transposed.Edges[i]
+			assert.Equal(t, e.ID, reversed.ID)
+			assert.Equal(t, e.Source, reversed.Target)
+			assert.Equal(t, e.Target, reversed.Source)
+			assert.Equal(t, e.Directed, reversed.Directed)
+			assert.Equal(t, e.Description, reversed.Description)
+			expected, err := e.GetAttributes()
+			require.NoError(t, err)
+			attrs, err := reversed.GetAttributes()
+			require.NoError(t, err)
+			assert.Equal(t, expected, attrs, "attributes of edge: %s", e.ID)
+			assert.Equal(t, reversed.SourceNode().ID, e.Target)
+		}
+		assert.NotNil(t, transposed.GetEdge("n1", "n0"))
+	}
+}
+
+func TestGraphFromAdjacencyMap(t *testing.T) {
+	adj := map[string][]string{
+		"b": {"c", "a"},
+		"a": {"b", "d"},
+		"c": {"c"},
+	}
+	gml, err := GraphFromAdjacencyMap(adj, true)
+	require.NoError(t, err)
+	require.Len(t, gml.Graphs, 1)
+	gr := gml.Graphs[0]
+	assert.Equal(t, "directed", gr.EdgeDefault)
+	require.Len(t, gr.Nodes, 4)
+	for i, id := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, id, gr.Nodes[i].ID)
+	}
+	require.Len(t, gr.Edges, 5)
+	expected := [][2]string{{"a", "b"}, {"a", "d"}, {"b", "c"}, {"b", "a"}, {"c", "c"}}
+	for i, pair := range expected {
+		assert.Equal(t, pair[0], gr.Edges[i].Source)
+		assert.Equal(t, pair[1], gr.Edges[i].Target)
+	}
+
+	// reverse adjacency is the same edge of undirected graph
+	gml, err = GraphFromAdjacencyMap(adj, false)
+	require.NoError(t, err)
+	gr = gml.Graphs[0]
+	assert.Equal(t, "undirected", gr.EdgeDefault)
+	assert.Len(t, gr.Nodes, 4)
+	assert.Len(t, gr.Edges, 4)
+	assert.Equal(t, gr.GetEdge("a", "b"), gr.GetEdge("b", "a"))
+
+	outBuf := &bytes.Buffer{}
+	err = gml.Encode(outBuf, false)
+	require.NoError(t, err)
+}
+
+func TestGraph_Merge(t *testing.T) {
+	gmlA := NewGraphML("")
+	a, err := gmlA.AddGraph("a", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = a.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": 1.0}, EdgeDirectionDefault, "a edge", true)
+	require.NoError(t, err)
+
+	gmlB := NewGraphML("")
+	_, err = gmlB.RegisterKey(KeyForNode, "size", "", reflect.Int, nil)
+	require.NoError(t, err)
+	b, err := gmlB.AddGraph("b", EdgeDirectionUndirected, map[string]interface{}{"name": "b"})
+	require.NoError(t, err)
+	_, err = b.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": 2.0}, EdgeDirectionDefault, "b edge", true)
+	require.NoError(t, err)
+	require.NoError(t, b.GetNode("n0").SetAttribute("size", 10))
+
+	err = a.Merge(b, "b_")
+	require.NoError(t, err)
+	assert.Len(t, a.Nodes, 4)
+	require.Len(t, a.Edges, 2)
+	edge := a.GetEdgeByID("b_e0")
+	require.NotNil(t, edge)
+	assert.Equal(t, "b_n0", edge.Source)
+	assert.Equal(t, "b_n1", edge.Target)
+	assert.Equal(t, "b edge", edge.Description)
+	// the edge stays undirected within directed graph
+	assert.Equal(t, "false", edge.Directed)
+	attrs, err := edge.GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"weight": 2.0}, attrs)
+	attrs, err = a.GetNode("b_n0").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 10}, attrs)
+	// the attributes of the other graph are not copied
+	attrs, err = a.GetAttributes()
+	require.NoError(t, err)
+	assert.Empty(t, attrs)
+
+	// IDs collision
+	err = a.Merge(b, "b_")
+	assert.EqualError(t, err, "node with ID: b_n0 already exists")
+	assert.Len(t, a.Nodes, 4)
+
+	// key type conflict
+	gmlC := NewGraphML("")
+	c, err := gmlC.AddGraph("c", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = c.AddEdgeByID("n0", "n1", map[string]interface{}{"weight": "heavy"}, EdgeDirectionDefault, "", true)
+	require.NoError(t, err)
+	err = a.Merge(c, "c_")
+	assert.EqualError(t, err, "key: weight has conflicting types: double, string")
+	assert.Len(t, a.Nodes, 4)
+	assert.Len(t, a.Edges, 2)
+}
+
+func TestUnion_KeysDifferingInDescription(t *testing.T) {
+	gmlA := NewGraphML("")
+	_, err := gmlA.RegisterKey(KeyForNode, "color", "color", reflect.String, "black")
+	require.NoError(t, err)
+	_, err = gmlA.RegisterKey(KeyForNode, "size", "the size of node", reflect.Int, nil)
+	require.NoError(t, err)
+	a, err := gmlA.AddGraph("a", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = a.AddNode(map[string]interface{}{"color": "red", "size": 1}, "")
+	require.NoError(t, err)
+
+	gmlB := NewGraphML("")
+	_, err = gmlB.RegisterKey(KeyForNode, "size", "size", reflect.Int, nil)
+	require.NoError(t, err)
+	_, err = gmlB.RegisterKey(KeyForNode, "color", "the fill color of node", reflect.String, "black")
+	require.NoError(t, err)
+	b, err := gmlB.AddGraph("b", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	_, err = b.AddNode(map[string]interface{}{"color": "blue"}, "")
+	require.NoError(t, err)
+	_, err = b.AddNode(map[string]interface{}{"color": "green", "size": 2}, "")
+	require.NoError(t, err)
+
+	u, err := Union(a, b)
+	require.NoError(t, err)
+	require.Len(t, u.parent.Keys, 2)
+	assert.Equal(t, "the fill color of node", u.parent.GetKey("color", KeyForNode).Description)
+	assert.Equal(t, "the size of node", u.parent.GetKey("size", KeyForNode).Description)
+	attrs, err := u.GetNode("n1").GetAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "green", "size": 2}, attrs)
+	assert.Equal(t, "d0", u.GetNode("n1").Data[0].Key)
+
+	// the source documents are intact
+	assert.Equal(t, "color", gmlA.GetKey("color", KeyForNode).Description)
+}
+
+const nestedGraphDoc = `<graphml>
+<key id="d0" for="node" attr.name="color" attr.type="string"/>
+<graph id="g0" edgedefault="directed">
+<node id="n0"><graph id="n0:g0" edgedefault="undirected">
+<node id="n0"><data key="d0">red</data><graph id="n0:n0:g0" edgedefault="directed"><node id="n0"/></graph></node>
+<node id="n1"/><edge source="n0" target="n1"/>
+</graph></node>
+<node id="n1"/>
+<edge source="n0" target="n1"/>
+</graph>
+</graphml>`
+
+// assertNestedGraphCopied checks that the node holds the copy of nested graph defined by nestedGraphDoc
+func assertNestedGraphCopied(t *testing.T, original, node *Node, graphID string) {
+	require.Len(t, node.Graphs, 1)
+	nested := node.Graphs[0]
+	assert.NotSame(t, original.Graphs[0], nested)
+	assert.Equal(t, graphID, nested.ID)
+	assert.Same(t, node, nested.ParentNode())
+	assert.Same(t, nested, node.graph.parent.GetGraph(graphID))
+	assert.Equal(t, 2, nested.NodeCount())
+	assert.Equal(t, 1, nested.EdgeCount())
+	assert.Equal(t, EdgeDirectionUndirected, nested.Edges[0].Direction())
+	color, _, err := nested.GetNode("n0").GetAttribute("color")
+	require.NoError(t, err)
+	assert.Equal(t, "red", color)
+	require.Len(t, nested.GetNode("n0").Graphs, 1)
+	assert.Equal(t, 1, nested.GetNode("n0").Graphs[0].NodeCount())
+}
+
+func TestGraph_CopyNestedGraphs(t *testing.T) {
+	gml := NewGraphML("")
+	require.NoError(t, gml.DecodeString(nestedGraphDoc))
+	gr := gml.Graphs[0]
+	original := gr.GetNode("n0")
+
+	filtered, err := gr.FilterSubgraph(nil, nil)
+	require.NoError(t, err)
+	assertNestedGraphCopied(t, original, filtered.GetNode("n0"), "n0:g0")
+	reversed, err := gr.Reversed()
+	require.NoError(t, err)
+	assertNestedGraphCopied(t, original, reversed.GetNode("n0"), "n0:g0")
+	// the nested graphs of excluded nodes are not copied
+	filtered, err = gr.FilterSubgraph(func(n *Node) bool { return n.ID == "n1" }, nil)
+	require.NoError(t, err)
+	assert.Nil(t, filtered.parent.GetGraph("n0:g0"))
+
+	// merge
+	target := NewGraphML("")
+	merged, err := target.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	require.NoError(t, merged.Merge(gr, "a:"))
+	assertNestedGraphCopied(t, original, merged.GetNode("a:n0"), "a:n0:g0")
+	assert.NotNil(t, target.GetGraph("a:n0:n0:g0"))
+	assert.Equal(t, "color", target.KeyByID(merged.GetNode("a:n0").Graphs[0].GetNode("n0").Data[0].Key).Name)
+	nodes := merged.NodeCount()
+	err = merged.Merge(gr, "a:")
+	assert.EqualError(t, err, "node with ID: a:n0 already exists")
+	other, err := target.AddGraph("", EdgeDirectionDirected, nil)
+	require.NoError(t, err)
+	err = other.Merge(gr, "a:")
+	assert.EqualError(t, err, "graph with ID: a:n0:g0 already exists")
+	assert.Equal(t, 0, other.NodeCount())
+	assert.Equal(t, nodes, merged.NodeCount())
+
+	// union adds nested graphs of missing nodes, renaming graphs with taken IDs
+	base := NewGraphML("")
+	require.NoError(t, base.DecodeString(`<graphml><graph id="g0" edgedefault="directed">
+<node id="x"><graph id="n0:g0" edgedefault="directed"/></node></graph></graphml>`))
+	u, err := Union(base.Graphs[0], gr)
+	require.NoError(t, err)
+	require.Len(t, u.GetNode("n0").Graphs, 1)
+	renamed := u.GetNode("n0").Graphs[0].ID
+	assert.NotEqual(t, "n0:g0", renamed)
+	assertNestedGraphCopied(t, original, u.GetNode("n0"), renamed)
+	assert.Equal(t, "n0:g0", u.GetNode("x").Graphs[0].ID)
+	assert.NotNil(t, u.parent.GetGraph("n0:n0:g0"))
+}